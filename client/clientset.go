@@ -3,11 +3,17 @@ package client
 import (
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
 	"sigs.k8s.io/kustomize/api/krusty"
 )
 
 type ClientSet struct {
-	Kustomizer      *krusty.Kustomizer
-	DynamicClient   dynamic.Interface
-	DiscoveryClient discovery.DiscoveryInterface
+	Kustomizer       *krusty.Kustomizer
+	DynamicClient    dynamic.Interface
+	DiscoveryClient  discovery.CachedDiscoveryInterface
+	RESTMapper       *restmapper.DeferredDiscoveryRESTMapper
+	FieldManager     string
+	ForceConflicts   bool
+	Parallelism      int
+	DefaultNamespace string
 }
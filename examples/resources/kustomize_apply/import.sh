@@ -0,0 +1,5 @@
+# The import ID is a kustomization directory or remote URL, which becomes the only entry of resources
+terraform import kustomize_apply.metallb "github.com/metallb/metallb/config/native?ref=v0.13.10"
+
+# The ID of a kustomize_apply imports the objects labeled with it, the kustomization is built from the configuration
+terraform import kustomize_apply.metallb 3f2c1e0b9d8a7f6e5d4c3b2a1f0e9d8c7b6a5f4e
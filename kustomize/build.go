@@ -0,0 +1,140 @@
+package kustomize
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+
+	"github.com/samber/lo"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/resmap"
+	ktypes "sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+
+	"github.com/webzyno/terraform-provider-kustomize/virtfs"
+)
+
+// BuildOptions are the attributes of kustomize_build and kustomize_apply which aren't part of the kustomization itself.
+type BuildOptions struct {
+	// BaseDir is the directory of the generated kustomization, defaulting to the working directory
+	BaseDir string
+	// Path of a kustomization directory the generated kustomization is laid on top of, relative to BaseDir
+	Path string
+	// InlineResources are manifests in YAML which are added to the resources of the generated kustomization
+	InlineResources []string
+	// Files are kept in memory relative to BaseDir, hiding files on disk at the same path
+	Files map[string]string
+}
+
+// BuildResult is the outcome of Build.
+type BuildResult struct {
+	ResMap resmap.ResMap
+	// Kustomization is the generated kustomization.yaml
+	Kustomization []byte
+	// Sources are SHA-256 hashes of the files on disk read during the build, keyed by path relative to the base directory
+	Sources map[string]string
+}
+
+// SourceFiles returns the paths of Sources in lexical order.
+func (r *BuildResult) SourceFiles() []string {
+	files := lo.Keys(r.Sources)
+	sort.Strings(files)
+	return files
+}
+
+// SourceHash returns a SHA-256 hash of the paths and contents of Sources.
+func (r *BuildResult) SourceHash() string {
+	hash := sha256.New()
+	for _, file := range r.SourceFiles() {
+		fmt.Fprintf(hash, "%s\x00%s\n", file, r.Sources[file])
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Build runs kustomize build on the kustomization generated from the attributes of kustomize_build and kustomize_apply,
+// and returns the built resources together with the generated kustomization.yaml and the files read from disk.
+// If a path is set, the kustomization directory at path is built directly when no other attribute is set. Otherwise it
+// becomes the first resource of the generated kustomization, so the attributes are applied on top of it.
+func Build(kustomizer *krusty.Kustomizer, kustomization ktypes.Kustomization, options BuildOptions) (*BuildResult, error) {
+	baseDir := options.BaseDir
+	if baseDir == "" {
+		var err error
+		if baseDir, err = os.Getwd(); err != nil {
+			return nil, err
+		}
+	}
+	fs, err := virtfs.NewOverlayFS(baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OverlayFS: %w", err)
+	}
+	baseDir = fs.BaseDir()
+
+	for name, content := range options.Files {
+		if err := fs.WriteMemoryFile(name, []byte(content)); err != nil {
+			return nil, fmt.Errorf("failed to write file %s to file system: %w", name, err)
+		}
+	}
+
+	// Inline resources are kept in memory next to the generated kustomization.yaml
+	for i, manifest := range options.InlineResources {
+		name := fmt.Sprintf("inline_resources_%d.yaml", i)
+		if err := fs.WriteMemoryFile(name, []byte(manifest)); err != nil {
+			return nil, fmt.Errorf("failed to write inline resource to file system: %w", err)
+		}
+		kustomization.Resources = append(kustomization.Resources, name)
+	}
+
+	path := options.Path
+	inline := !isEmpty(kustomization)
+	if path != "" {
+		kustomization.Resources = append([]string{path}, kustomization.Resources...)
+	}
+	kustomizationContent, err := yaml.Marshal(&kustomization)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected error during marshaling kustomization.yaml: %w", err)
+	}
+
+	root := baseDir
+	if path != "" {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(baseDir, path)
+		}
+		// The generated kustomization.yaml would hide the one on disk
+		if inline && filepath.Clean(path) == baseDir {
+			return nil, fmt.Errorf("path %s must not be the base directory when other kustomization attributes are set", path)
+		}
+		if !inline {
+			root = path
+		}
+	}
+
+	if inline || path == "" {
+		if err := fs.WriteMemoryFile(virtfs.KUSTOMIZATION, kustomizationContent); err != nil {
+			return nil, fmt.Errorf("failed to write kustomization.yaml to file system: %w", err)
+		}
+	}
+	// Kustomize writes to the memory layer only, Helm chart inflation would bypass fs and pull charts to disk
+	resMap, err := kustomizer.Run(fs, root)
+	if err != nil {
+		return nil, err
+	}
+	return &BuildResult{
+		ResMap:        resMap,
+		Kustomization: kustomizationContent,
+		Sources:       fs.Sources(),
+	}, nil
+}
+
+// isEmpty reports whether kustomization sets nothing but its kind and API version.
+func isEmpty(kustomization ktypes.Kustomization) bool {
+	content, err := yaml.Marshal(&kustomization)
+	if err != nil {
+		return false
+	}
+	empty, err := yaml.Marshal(&ktypes.Kustomization{TypeMeta: kustomization.TypeMeta})
+	return err == nil && reflect.DeepEqual(content, empty)
+}
@@ -0,0 +1,92 @@
+package kustomize
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/krusty"
+	ktypes "sigs.k8s.io/kustomize/api/types"
+)
+
+// snapshotDir returns the contents of every file and directory below dir, keyed by relative path.
+func snapshotDir(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	snapshot := map[string]string{}
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			snapshot[rel] = "<dir>"
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		snapshot[rel] = string(content)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return snapshot
+}
+
+func TestBuildLeavesWorkingTreeUnchanged(t *testing.T) {
+	baseDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(baseDir, "app"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"kustomization.yaml":     "resources: [app]\n",
+		"app/kustomization.yaml": "resources: [configmap.yaml]\n",
+		"app/configmap.yaml":     "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n",
+	} {
+		if err := os.WriteFile(filepath.Join(baseDir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	before := snapshotDir(t, baseDir)
+
+	options := krusty.MakeDefaultOptions()
+	options.Reorder = krusty.ReorderOptionLegacy
+	kustomizer := krusty.MakeKustomizer(options)
+
+	result, err := Build(kustomizer, ktypes.Kustomization{
+		NamePrefix: "prefix-",
+		ConfigMapGenerator: []ktypes.ConfigMapArgs{{
+			GeneratorArgs: ktypes.GeneratorArgs{
+				Name:          "generated",
+				KvPairSources: ktypes.KvPairSources{FileSources: []string{"generated/config.properties"}},
+			},
+		}},
+	}, BuildOptions{
+		BaseDir:         baseDir,
+		Path:            "app",
+		InlineResources: []string{"apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: inline\n"},
+		Files:           map[string]string{"generated/config.properties": "key=value\n", "app/configmap.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: memory\n"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := result.ResMap.Size(); size != 3 {
+		t.Errorf("built %d objects, want 3", size)
+	}
+
+	// Helm chart inflation isn't enabled, so it fails before helm could pull charts to disk
+	if _, err := Build(kustomizer, ktypes.Kustomization{
+		HelmCharts: []ktypes.HelmChart{{Name: "chart", Repo: "https://charts.example.com"}},
+	}, BuildOptions{BaseDir: baseDir}); err == nil || !strings.Contains(err.Error(), "enable-helm") {
+		t.Errorf("build with helm_charts error = %v, want an error as Helm isn't enabled", err)
+	}
+
+	if after := snapshotDir(t, baseDir); !reflect.DeepEqual(before, after) {
+		t.Errorf("working tree changed by build:\nbefore: %v\nafter:  %v", before, after)
+	}
+}
@@ -0,0 +1,20 @@
+package kustomize
+
+import (
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// ObjectID identifies a Kubernetes object by its API version, kind, namespace and name, e.g. apps/v1/Deployment/ns/name.
+// The namespace is left out for objects without one.
+func ObjectID(apiVersion string, kind string, namespace string, name string) string {
+	if namespace == "" {
+		return strings.Join([]string{apiVersion, kind, name}, "/")
+	}
+	return strings.Join([]string{apiVersion, kind, namespace, name}, "/")
+}
+
+func ResourceID(res *resource.Resource) string {
+	return ObjectID(res.GetGvk().ApiVersion(), res.GetKind(), res.GetNamespace(), res.GetName())
+}
@@ -0,0 +1,72 @@
+package kustomize
+
+import (
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// RedactedValue replaces every value of Secret data and stringData in attributes which aren't sensitive.
+const RedactedValue = "(sensitive value)"
+
+// SecretFields are the fields of a Secret holding its values.
+var SecretFields = []string{"data", "stringData"}
+
+func IsSecret(group string, kind string) bool {
+	return group == "" && kind == "Secret"
+}
+
+// RedactedYaml returns the manifests of resMap in YAML with Secret values redacted, together with the original Secrets
+// in YAML keyed by ResourceID. resMap itself is left untouched, so the real values are still applied.
+func RedactedYaml(resMap resmap.ResMap) ([]byte, map[string]string, error) {
+	redacted, secrets, err := RedactSecrets(resMap)
+	if err != nil {
+		return nil, nil, err
+	}
+	manifests, err := redacted.AsYaml()
+	if err != nil {
+		return nil, nil, err
+	}
+	return manifests, secrets, nil
+}
+
+// RedactSecrets returns a copy of resMap with Secret values redacted, together with the original Secrets in YAML keyed
+// by ResourceID.
+func RedactSecrets(resMap resmap.ResMap) (resmap.ResMap, map[string]string, error) {
+	redacted := resMap.DeepCopy()
+	secrets := map[string]string{}
+	for _, res := range redacted.Resources() {
+		if !IsSecret(res.GetGvk().Group, res.GetKind()) {
+			continue
+		}
+		secret, err := res.AsYAML()
+		if err != nil {
+			return nil, nil, err
+		}
+		secrets[ResourceID(res)] = string(secret)
+		if err := redactResource(res); err != nil {
+			return nil, nil, err
+		}
+	}
+	return redacted, secrets, nil
+}
+
+func redactResource(res *resource.Resource) error {
+	for _, field := range SecretFields {
+		values, err := res.Pipe(yaml.Lookup(field))
+		if err != nil {
+			return err
+		}
+		if values == nil {
+			continue
+		}
+		if err := values.VisitFields(func(node *yaml.MapNode) error {
+			node.Value.YNode().Tag = yaml.NodeTagString
+			node.Value.YNode().Value = RedactedValue
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
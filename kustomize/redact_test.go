@@ -0,0 +1,69 @@
+package kustomize
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/provider"
+	"sigs.k8s.io/kustomize/api/resmap"
+)
+
+const redactManifests = `apiVersion: v1
+kind: Secret
+metadata:
+  name: credentials
+  namespace: default
+data:
+  password: c2VjcmV0
+stringData:
+  token: plain-token
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  password: not-a-secret
+`
+
+func TestRedactSecrets(t *testing.T) {
+	resMap, err := resmap.NewFactory(provider.NewDefaultDepProvider().GetResourceFactory()).NewResMapFromBytes([]byte(redactManifests))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	redacted, secrets, err := RedactSecrets(resMap)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret := redacted.Resources()[0]
+	data, err := secret.GetFieldValue("data.password")
+	if err != nil || data != RedactedValue {
+		t.Errorf("data.password = %v (%v), want %q", data, err, RedactedValue)
+	}
+	stringData, err := secret.GetFieldValue("stringData.token")
+	if err != nil || stringData != RedactedValue {
+		t.Errorf("stringData.token = %v (%v), want %q", stringData, err, RedactedValue)
+	}
+	configMap, err := redacted.Resources()[1].GetFieldValue("data.password")
+	if err != nil || configMap != "not-a-secret" {
+		t.Errorf("ConfigMap data.password = %v (%v), want it untouched", configMap, err)
+	}
+
+	original, err := resMap.Resources()[0].GetFieldValue("data.password")
+	if err != nil || original != "c2VjcmV0" {
+		t.Errorf("original data.password = %v (%v), want resMap untouched", original, err)
+	}
+
+	if len(secrets) != 1 {
+		t.Fatalf("got %d secrets, want 1", len(secrets))
+	}
+	manifest, found := secrets[ResourceID(resMap.Resources()[0])]
+	if !found {
+		t.Fatalf("secret %s not found in %v", ResourceID(resMap.Resources()[0]), secrets)
+	}
+	if !strings.Contains(manifest, "c2VjcmV0") || !strings.Contains(manifest, "plain-token") {
+		t.Errorf("secret manifest doesn't hold the original values:\n%s", manifest)
+	}
+}
@@ -0,0 +1,86 @@
+package apply
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var deletePropagationPolicies = map[string]metav1.DeletionPropagation{
+	"foreground": metav1.DeletePropagationForeground,
+	"background": metav1.DeletePropagationBackground,
+	"orphan":     metav1.DeletePropagationOrphan,
+}
+
+func getDeleteOptions(data KustomizeApplyModel) metav1.DeleteOptions {
+	policy, ok := deletePropagationPolicies[data.DeletePropagation.ValueString()]
+	if !ok {
+		return metav1.DeleteOptions{}
+	}
+	return metav1.DeleteOptions{PropagationPolicy: &policy}
+}
+
+// deleteObjects deletes objects in the given order, objects which are already gone are skipped. This includes objects
+// whose kind is no longer served, e.g. because their CRD was deleted.
+// When wait is set, it blocks until every object has disappeared from the cluster.
+func (r *KustomizeApplyResource) deleteObjects(ctx context.Context, items []InventoryModel, options metav1.DeleteOptions, wait bool) error {
+	for i, item := range items {
+		restClient, err := r.getRestClientFromUnstructured(item.Unstructured())
+		if meta.IsNoMatchError(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create rest client from object %s: %w", objectName(item.Unstructured()), err)
+		}
+		if err := restClient.Delete(ctx, item.Name.ValueString(), options); err != nil && !apierrors.IsNotFound(err) {
+			if ctx.Err() != nil {
+				return errors.New(pendingInventoryError("deleting", items[i:]))
+			}
+			return fmt.Errorf("failed to delete object %s: %w", objectName(item.Unstructured()), err)
+		}
+	}
+
+	if !wait {
+		return nil
+	}
+	pending := items
+	for {
+		var stillPending []InventoryModel
+		for _, item := range pending {
+			restClient, err := r.getRestClientFromUnstructured(item.Unstructured())
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to create rest client from object %s: %w", objectName(item.Unstructured()), err)
+			}
+			live, err := restClient.Get(ctx, item.Name.ValueString(), metav1.GetOptions{})
+			switch {
+			case apierrors.IsNotFound(err):
+			case err != nil && ctx.Err() != nil:
+				stillPending = append(stillPending, item)
+			case err != nil:
+				return fmt.Errorf("failed to get object %s: %w", objectName(item.Unstructured()), err)
+			// An object recreated by someone else under the same name is not ours to wait for
+			case item.Uid.ValueString() == "" || string(live.GetUID()) == item.Uid.ValueString():
+				stillPending = append(stillPending, item)
+			}
+		}
+
+		pending = stillPending
+		if len(pending) == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.New(pendingInventoryError("waiting for deletion of", pending))
+		case <-time.After(waitPollInterval):
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package apply
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+	"sigs.k8s.io/structured-merge-diff/v4/value"
+)
+
+// Top-level fields which are never compared against the live object.
+// status is owned by controllers and stringData is write-only, it is merged into data by the API server.
+var driftIgnoredFields = []string{"status", "stringData"}
+
+func decodeManifests(manifests string) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewBufferString(manifests), 4096)
+	for {
+		var object map[string]interface{}
+		if err := decoder.Decode(&object); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to decode manifests: %w", err)
+		}
+		if len(object) == 0 {
+			continue
+		}
+		normalized, err := normalizeObject(object)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, &unstructured.Unstructured{Object: normalized})
+	}
+	return objects, nil
+}
+
+func decodeObjects(objects []string) ([]*unstructured.Unstructured, error) {
+	var result []*unstructured.Unstructured
+	for _, object := range objects {
+		var content map[string]interface{}
+		if err := json.Unmarshal([]byte(object), &content); err != nil {
+			return nil, fmt.Errorf("failed to decode object: %w", err)
+		}
+		result = append(result, &unstructured.Unstructured{Object: content})
+	}
+	return result, nil
+}
+
+// normalizeObject round-trips object through JSON so numbers are compared as float64 on both sides.
+func normalizeObject(object map[string]interface{}) (map[string]interface{}, error) {
+	content, err := json.Marshal(object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert object to JSON: %w", err)
+	}
+	var normalized map[string]interface{}
+	if err := json.Unmarshal(content, &normalized); err != nil {
+		return nil, fmt.Errorf("failed to convert JSON to object: %w", err)
+	}
+	return normalized, nil
+}
+
+// isSameObject reports whether desired and live identify the same Kubernetes object.
+// An empty namespace in desired matches any namespace since the API server defaults it.
+func isSameObject(desired *unstructured.Unstructured, live *unstructured.Unstructured) bool {
+	if desired.GroupVersionKind().GroupKind() != live.GroupVersionKind().GroupKind() || desired.GetName() != live.GetName() {
+		return false
+	}
+	return desired.GetNamespace() == "" || desired.GetNamespace() == live.GetNamespace()
+}
+
+// hasDrift reports whether any desired manifest is missing from live objects or has fields changed outside of Terraform.
+// Only fields applied by fieldManager are compared, fields taken over by controllers or other managers are left to them.
+func hasDrift(desired []*unstructured.Unstructured, live []*unstructured.Unstructured, fieldManager string) bool {
+	for _, d := range desired {
+		found := false
+		for _, l := range live {
+			if !isSameObject(d, l) {
+				continue
+			}
+			found = true
+			if !isObjectSubset(d, l, fieldManager) {
+				return true
+			}
+			break
+		}
+		if !found {
+			return true
+		}
+	}
+	return false
+}
+
+// isObjectSubset compares the fields of desired which fieldManager owns according to the managed fields of live.
+// Objects without managed fields of fieldManager are compared field by field.
+func isObjectSubset(desired *unstructured.Unstructured, live *unstructured.Unstructured, fieldManager string) bool {
+	owned := managedFieldSet(live, fieldManager)
+	for key, value := range desired.Object {
+		if lo.Contains(driftIgnoredFields, key) {
+			continue
+		}
+		if owned == nil {
+			if !isSubset(value, live.Object[key]) {
+				return false
+			}
+			continue
+		}
+		key := key
+		if !isOwnedSubset(fieldpath.PathElement{FieldName: &key}, owned, value, live.Object[key]) {
+			return false
+		}
+	}
+	return true
+}
+
+// managedFieldSet returns the fields of live which fieldManager applied, or nil if there are none.
+func managedFieldSet(live *unstructured.Unstructured, fieldManager string) *fieldpath.Set {
+	for _, entry := range live.GetManagedFields() {
+		if entry.Manager != fieldManager || entry.Operation != metav1.ManagedFieldsOperationApply || entry.Subresource != "" || entry.FieldsV1 == nil {
+			continue
+		}
+		set := &fieldpath.Set{}
+		if err := set.FromJSON(bytes.NewReader(entry.FieldsV1.Raw)); err != nil {
+			return nil
+		}
+		return set
+	}
+	return nil
+}
+
+// isAppliedBy reports whether fieldManager has applied fields of every object according to their managed fields.
+func isAppliedBy(objects []*unstructured.Unstructured, fieldManager string) bool {
+	return lo.EveryBy(objects, func(object *unstructured.Unstructured) bool { return managedFieldSet(object, fieldManager) != nil })
+}
+
+// isOwnedSubset is isSubset limited to the fields below pe which are in set.
+func isOwnedSubset(pe fieldpath.PathElement, set *fieldpath.Set, desired interface{}, live interface{}) bool {
+	owned, ok := set.Children.Get(pe)
+	if !ok {
+		return !set.Members.Has(pe) || isSubset(desired, live)
+	}
+
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		l, _ := live.(map[string]interface{})
+		for key, value := range d {
+			key := key
+			if !isOwnedSubset(fieldpath.PathElement{FieldName: &key}, owned, value, l[key]) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		l, _ := live.([]interface{})
+		for i, value := range d {
+			element, ok := findPathElement(owned, value, i)
+			if !ok {
+				continue
+			}
+			_, liveIndex, found := lo.FindIndexOf(l, func(item interface{}) bool { return matchesPathElement(element, item, -1) })
+			if element.Index != nil {
+				liveIndex, found = *element.Index, *element.Index < len(l)
+			}
+			if !found {
+				return false
+			}
+			if !isOwnedSubset(element, owned, value, l[liveIndex]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return isSubset(desired, live)
+	}
+}
+
+// findPathElement returns the path element in set which selects the list item at index.
+func findPathElement(set *fieldpath.Set, item interface{}, index int) (fieldpath.PathElement, bool) {
+	var found *fieldpath.PathElement
+	match := func(pe fieldpath.PathElement) {
+		if found == nil && matchesPathElement(pe, item, index) {
+			found = &pe
+		}
+	}
+	set.Children.Iterate(match)
+	set.Members.Iterate(match)
+	if found == nil {
+		return fieldpath.PathElement{}, false
+	}
+	return *found, true
+}
+
+// matchesPathElement reports whether pe selects the list item at index, a negative index matches no index.
+func matchesPathElement(pe fieldpath.PathElement, item interface{}, index int) bool {
+	switch {
+	case pe.Key != nil:
+		fields, ok := item.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for _, field := range *pe.Key {
+			if !value.Equals(field.Value, value.NewValueInterface(fields[field.Name])) {
+				return false
+			}
+		}
+		return true
+	case pe.Value != nil:
+		return value.Equals(*pe.Value, value.NewValueInterface(item))
+	case pe.Index != nil:
+		return *pe.Index == index
+	}
+	return false
+}
+
+// isSubset reports whether every field set in desired has the same value in live.
+// Fields only present in live are defaulted or managed by someone else and are ignored.
+func isSubset(desired interface{}, live interface{}) bool {
+	switch d := desired.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		l, ok := live.(map[string]interface{})
+		if !ok {
+			return live == nil && len(d) == 0
+		}
+		for key, value := range d {
+			if !isSubset(value, l[key]) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		l, ok := live.([]interface{})
+		if !ok {
+			return live == nil && len(d) == 0
+		}
+		if len(d) != len(l) {
+			return false
+		}
+		for i := range d {
+			if !isSubset(d[i], l[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(desired, live) || isSameQuantity(desired, live)
+	}
+}
+
+// isSameQuantity reports whether desired and live are the same resource quantity, as the API server returns quantities
+// in canonical form, e.g. cpu: 1000m is returned as "1".
+func isSameQuantity(desired interface{}, live interface{}) bool {
+	l, ok := live.(string)
+	if !ok {
+		return false
+	}
+	var d string
+	switch v := desired.(type) {
+	case string:
+		d = v
+	case float64:
+		d = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return false
+	}
+	desiredQuantity, err := resource.ParseQuantity(d)
+	if err != nil {
+		return false
+	}
+	liveQuantity, err := resource.ParseQuantity(l)
+	return err == nil && desiredQuantity.Cmp(liveQuantity) == 0
+}
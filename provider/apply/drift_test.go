@@ -0,0 +1,176 @@
+package apply
+
+import "testing"
+
+func TestIsSubset(t *testing.T) {
+	tests := []struct {
+		name    string
+		desired interface{}
+		live    interface{}
+		want    bool
+	}{
+		{name: "equal scalars", desired: "nginx:1.25", live: "nginx:1.25", want: true},
+		{name: "changed scalar", desired: "nginx:1.25", live: "nginx:1.24", want: false},
+		{name: "nil desired", desired: nil, live: "defaulted", want: true},
+		{name: "extra live fields", desired: map[string]interface{}{"a": "1"}, live: map[string]interface{}{"a": "1", "b": "2"}, want: true},
+		{name: "missing live field", desired: map[string]interface{}{"a": "1"}, live: map[string]interface{}{}, want: false},
+		{name: "empty map and nil", desired: map[string]interface{}{}, live: nil, want: true},
+		{name: "list length", desired: []interface{}{"a"}, live: []interface{}{"a", "b"}, want: false},
+		{name: "list items", desired: []interface{}{map[string]interface{}{"a": "1"}}, live: []interface{}{map[string]interface{}{"a": "1", "b": "2"}}, want: true},
+		{name: "numbers", desired: float64(3), live: float64(3), want: true},
+		{name: "canonical cpu quantity", desired: "1000m", live: "1", want: true},
+		{name: "canonical memory quantity", desired: "1024Mi", live: "1Gi", want: true},
+		{name: "number quantity", desired: float64(2), live: "2", want: true},
+		{name: "different quantity", desired: "500m", live: "1", want: false},
+		{name: "not a quantity", desired: "foo", live: "bar", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isSubset(tt.desired, tt.live); got != tt.want {
+				t.Errorf("isSubset(%v, %v) = %v, want %v", tt.desired, tt.live, got, tt.want)
+			}
+		})
+	}
+}
+
+const driftDesired = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: default
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:1.0
+        resources:
+          requests:
+            cpu: 1000m
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: webhook
+webhooks:
+- name: webhook.example.com
+  clientConfig:
+    caBundle: ""
+`
+
+// driftLive returns live objects for driftDesired, with image and caBundle replaced. Only the fields in driftDesired
+// except caBundle are owned by the field manager "test".
+func driftLive(image string, caBundle string) string {
+	return `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: default
+  managedFields:
+  - manager: test
+    operation: Apply
+    apiVersion: apps/v1
+    fieldsType: FieldsV1
+    fieldsV1:
+      f:spec:
+        f:replicas: {}
+        f:template:
+          f:spec:
+            f:containers:
+              k:{"name":"app"}:
+                .: {}
+                f:name: {}
+                f:image: {}
+                f:resources:
+                  f:requests:
+                    f:cpu: {}
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - name: sidecar
+        image: sidecar:1.0
+      - name: app
+        image: ` + image + `
+        imagePullPolicy: IfNotPresent
+        resources:
+          requests:
+            cpu: "1"
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: webhook
+  managedFields:
+  - manager: test
+    operation: Apply
+    apiVersion: admissionregistration.k8s.io/v1
+    fieldsType: FieldsV1
+    fieldsV1:
+      f:webhooks:
+        k:{"name":"webhook.example.com"}:
+          .: {}
+          f:name: {}
+  - manager: cainjector
+    operation: Update
+    apiVersion: admissionregistration.k8s.io/v1
+    fieldsType: FieldsV1
+    fieldsV1:
+      f:webhooks:
+        k:{"name":"webhook.example.com"}:
+          f:clientConfig:
+            f:caBundle: {}
+webhooks:
+- name: webhook.example.com
+  clientConfig:
+    caBundle: ` + caBundle + `
+`
+}
+
+func TestHasDrift(t *testing.T) {
+	desired, err := decodeManifests(driftDesired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name         string
+		live         string
+		fieldManager string
+		want         bool
+	}{
+		{name: "in sync", live: driftLive("app:1.0", "Y2E="), fieldManager: "test", want: false},
+		{name: "owned field changed", live: driftLive("app:2.0", "Y2E="), fieldManager: "test", want: true},
+		{name: "unowned fields compared without managed fields", live: driftLive("app:1.0", "Y2E="), fieldManager: "other", want: true},
+		{name: "missing object", live: "", fieldManager: "test", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			live, err := decodeManifests(tt.live)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hasDrift(desired, live, tt.fieldManager); got != tt.want {
+				t.Errorf("hasDrift() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsAppliedBy(t *testing.T) {
+	live, err := decodeManifests(driftLive("app:1.0", "Y2E="))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isAppliedBy(live, "test") {
+		t.Error("isAppliedBy() = false for the manager which applied every object")
+	}
+	// cainjector only updated the webhook, it didn't apply anything
+	if isAppliedBy(live, "cainjector") {
+		t.Error("isAppliedBy() = true for a manager without apply operations")
+	}
+	if isAppliedBy(live, "other") {
+		t.Error("isAppliedBy() = true for a manager which owns no fields")
+	}
+}
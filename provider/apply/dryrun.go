@@ -0,0 +1,184 @@
+package apply
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/samber/lo"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/kustomize/api/resmap"
+)
+
+// Fields which always differ between live and dry-run objects or are not owned by the applier
+var dryRunIgnoredFields = [][]string{
+	{"metadata", "managedFields"},
+	{"metadata", "resourceVersion"},
+	{"metadata", "generation"},
+	{"status"},
+}
+
+// dryRunApply sends every object to the API server as a dry-run server-side apply and compares the result with the live object.
+// Objects which would be created or changed are reported in a single warning, rejected objects are reported as errors.
+func (r *KustomizeApplyResource) dryRunApply(ctx context.Context, resMap resmap.ResMap, options metav1.ApplyOptions) diag.Diagnostics {
+	var diagnostics diag.Diagnostics
+	var changes []string
+
+	// Custom resources of CRDs in the same build can't be validated until their CRD is established
+	crdKinds := map[string]bool{}
+	for _, res := range resMap.Resources() {
+		if !isCRD(res) {
+			continue
+		}
+		group, _ := res.GetString("spec.group")
+		kind, _ := res.GetString("spec.names.kind")
+		crdKinds[group+"/"+kind] = true
+	}
+
+	// Objects in Namespaces of the same build which don't exist yet are rejected by NamespaceLifecycle admission
+	pendingNamespaces, namespaceDiagnostics := r.pendingNamespaces(ctx, resMap)
+	if diagnostics.Append(namespaceDiagnostics...); diagnostics.HasError() {
+		return diagnostics
+	}
+
+	for _, res := range resMap.Resources() {
+		restClient, err := r.getRestClientFromResource(res)
+		if err != nil && crdKinds[res.GetGvk().Group+"/"+res.GetKind()] {
+			changes = append(changes, fmt.Sprintf("+ %s will be created after its CustomResourceDefinition", resourceName(res)))
+			continue
+		}
+		if err != nil {
+			diagnostics.AddError("Failed to create rest client from resource", fmt.Sprintf("%s: %s", resourceName(res), err.Error()))
+			continue
+		}
+		if !isNamespace(res) && pendingNamespaces[res.GetNamespace()] {
+			changes = append(changes, fmt.Sprintf("+ %s will be created after its Namespace", resourceName(res)))
+			continue
+		}
+		desired, err := kustomizeResourceToUnstructured(res)
+		if err != nil {
+			diagnostics.AddError("Failed to convert Kustomize resource to unstructured", err.Error())
+			continue
+		}
+
+		dryRunOptions := options
+		dryRunOptions.DryRun = []string{metav1.DryRunAll}
+		dryRun, err := restClient.Apply(ctx, desired.GetName(), desired, dryRunOptions)
+		if err != nil {
+			diagnostics.AddError("Server-side dry-run apply failed", fmt.Sprintf("%s: %s", resourceName(res), err.Error()))
+			continue
+		}
+		live, err := restClient.Get(ctx, desired.GetName(), metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				changes = append(changes, fmt.Sprintf("+ %s will be created", resourceName(res)))
+				continue
+			}
+			diagnostics.AddError("Failed to get object", fmt.Sprintf("%s: %s", resourceName(res), err.Error()))
+			continue
+		}
+
+		// Secret values must not show up in plan output
+		if fields := diffObjects(withoutIgnoredFields(redactObject(live)), withoutIgnoredFields(redactObject(dryRun))); len(fields) > 0 {
+			changes = append(changes, fmt.Sprintf("~ %s will be changed\n    %s", resourceName(res), strings.Join(fields, "\n    ")))
+		}
+	}
+
+	if len(changes) > 0 {
+		diagnostics.AddWarning("Server-side dry-run detected changes", strings.Join(changes, "\n"))
+	}
+	return diagnostics
+}
+
+// pendingNamespaces returns the names of Namespaces in resMap which don't exist in the cluster yet.
+func (r *KustomizeApplyResource) pendingNamespaces(ctx context.Context, resMap resmap.ResMap) (map[string]bool, diag.Diagnostics) {
+	var diagnostics diag.Diagnostics
+	pending := map[string]bool{}
+	for _, res := range resMap.Resources() {
+		if !isNamespace(res) {
+			continue
+		}
+		restClient, err := r.getRestClientFromResource(res)
+		if err != nil {
+			diagnostics.AddError("Failed to create rest client from resource", fmt.Sprintf("%s: %s", resourceName(res), err.Error()))
+			continue
+		}
+		if _, err := restClient.Get(ctx, res.GetName(), metav1.GetOptions{}); err != nil {
+			if apierrors.IsNotFound(err) {
+				pending[res.GetName()] = true
+				continue
+			}
+			diagnostics.AddError("Failed to get object", fmt.Sprintf("%s: %s", resourceName(res), err.Error()))
+		}
+	}
+	return pending, diagnostics
+}
+
+func withoutIgnoredFields(object *unstructured.Unstructured) map[string]interface{} {
+	copied := object.DeepCopy()
+	for _, field := range dryRunIgnoredFields {
+		unstructured.RemoveNestedField(copied.Object, field...)
+	}
+	return copied.Object
+}
+
+// diffObjects returns one line per field which differs between before and after.
+func diffObjects(before map[string]interface{}, after map[string]interface{}) []string {
+	return diffValues("", before, after)
+}
+
+func diffValues(path string, before interface{}, after interface{}) []string {
+	if reflect.DeepEqual(before, after) {
+		return nil
+	}
+
+	switch a := after.(type) {
+	case map[string]interface{}:
+		b, ok := before.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := lo.Uniq(append(lo.Keys(b), lo.Keys(a)...))
+		sort.Strings(keys)
+		var lines []string
+		for _, key := range keys {
+			lines = append(lines, diffValues(joinFieldPath(path, key), b[key], a[key])...)
+		}
+		return lines
+	case []interface{}:
+		b, ok := before.([]interface{})
+		if !ok || len(a) != len(b) {
+			break
+		}
+		var lines []string
+		for i := range a {
+			lines = append(lines, diffValues(fmt.Sprintf("%s[%d]", path, i), b[i], a[i])...)
+		}
+		return lines
+	}
+	return []string{fmt.Sprintf("%s: %s => %s", path, formatFieldValue(before), formatFieldValue(after))}
+}
+
+func joinFieldPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func formatFieldValue(value interface{}) string {
+	if value == nil {
+		return "(none)"
+	}
+	content, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(content)
+}
@@ -0,0 +1,58 @@
+package apply
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// ID assigned by earlier versions of the provider to every kustomize_apply, replaced on the next plan
+const legacyID = "test"
+
+// Label set to the resource ID on every applied object, so the objects of a kustomize_apply can be found by its ID
+const inventoryIDLabel = "terraform-provider-kustomize/id"
+
+// Length of resource IDs, a truncated SHA-256 hash fits into a label value
+const inventoryIDLength = 40
+
+var inventoryIDPattern = regexp.MustCompile(fmt.Sprintf("^[0-9a-f]{%d}$", inventoryIDLength))
+
+// inventoryID derives the resource ID from the identity of every generated object.
+// API versions are left out, so the ID stays stable when objects move to a newer version, and the order of objects
+// doesn't matter. Importing the same kustomization results in the same ID.
+func inventoryID(resMap resmap.ResMap) string {
+	keys := lo.Map(resMap.Resources(), func(res *resource.Resource, _ int) string {
+		return strings.Join([]string{res.GetGvk().Group, res.GetKind(), res.GetNamespace(), res.GetName()}, "/")
+	})
+	sort.Strings(keys)
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join(keys, "\n"))))[:inventoryIDLength]
+}
+
+// isInventoryID reports whether id has the format of resource IDs assigned by inventoryID.
+func isInventoryID(id string) bool {
+	return inventoryIDPattern.MatchString(id)
+}
+
+// labelResources sets the inventory ID label of every resource in resMap to id.
+func labelResources(resMap resmap.ResMap, id string) error {
+	for _, res := range resMap.Resources() {
+		labels := res.GetLabels()
+		labels[inventoryIDLabel] = id
+		if err := res.SetLabels(labels); err != nil {
+			return fmt.Errorf("failed to label %s: %w", resourceName(res), err)
+		}
+	}
+	return nil
+}
+
+// isLabeled reports whether every object carries the inventory ID label set to id.
+func isLabeled(objects []*unstructured.Unstructured, id string) bool {
+	return lo.EveryBy(objects, func(object *unstructured.Unstructured) bool { return object.GetLabels()[inventoryIDLabel] == id })
+}
@@ -0,0 +1,31 @@
+package apply
+
+import "testing"
+
+func TestIsLabeled(t *testing.T) {
+	objects, err := decodeManifests(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: labeled
+  labels:
+    terraform-provider-kustomize/id: 0123456789abcdef0123456789abcdef01234567
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: unlabeled
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const id = "0123456789abcdef0123456789abcdef01234567"
+	if !isLabeled(objects[:1], id) {
+		t.Error("isLabeled() = false for an object labeled with the ID")
+	}
+	if isLabeled(objects[:1], "ffffffffffffffffffffffffffffffffffffffff") {
+		t.Error("isLabeled() = true for an object labeled with another ID")
+	}
+	if isLabeled(objects, id) {
+		t.Error("isLabeled() = true although an object has no label")
+	}
+}
@@ -0,0 +1,211 @@
+package apply
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/samber/lo"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/csaupgrade"
+)
+
+// Field managers used by kubectl for client-side apply and imperative commands.
+// Fields owned by them are migrated to the provider's field manager on import.
+var clientSideFieldManagers = sets.New(
+	"kubectl-client-side-apply",
+	"kubectl-create",
+	"kubectl-edit",
+	"kubectl-patch",
+	"kubectl-label",
+	"kubectl-annotate",
+	"before-first-apply",
+)
+
+// ImportState imports objects deployed by `kustomize build | kubectl apply -f`.
+// The import ID is a kustomization directory or remote URL, which becomes the only entry of resources.
+// The ID of a kustomize_apply imports the objects labeled with it instead, e.g. after its state was lost.
+func (r *KustomizeApplyResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	if isInventoryID(req.ID) {
+		r.importInventory(ctx, req.ID, resp)
+		return
+	}
+
+	data := KustomizeApplyModel{
+		Resources: []types.String{types.StringValue(req.ID)},
+	}
+
+	// Run kustomize build and save resmap in yaml
+	result, err := kustomizeBuild(r.kustomizer, data)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to run kustomize build",
+			err.Error(),
+		)
+		return
+	}
+	resMap := result.ResMap
+	attributes, diagnostics := newBuildAttributes(ctx, result)
+	if resp.Diagnostics.Append(diagnostics...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.discoveryDiagnostics()...)
+
+	fieldManager := r.getApplyOptions(data).FieldManager
+	var objects []*unstructured.Unstructured
+	var missing []string
+	for _, res := range resMap.Resources() {
+		restClient, err := r.getRestClientFromResource(res)
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to create rest client from resource", err.Error())
+			return
+		}
+
+		live, err := restClient.Get(ctx, res.GetName(), metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				missing = append(missing, resourceName(res))
+				continue
+			}
+			resp.Diagnostics.AddError("Failed to get object", err.Error())
+			return
+		}
+
+		if live, err = migrateFieldManagers(ctx, restClient, live, fieldManager); err != nil {
+			resp.Diagnostics.AddError("Failed to migrate field managers of object", err.Error())
+			return
+		}
+
+		objects = append(objects, live)
+	}
+	if len(missing) > 0 {
+		resp.Diagnostics.AddError(
+			"Failed to find objects to import",
+			"The following objects generated by kustomize build don't exist in the cluster: "+strings.Join(missing, ", "),
+		)
+		return
+	}
+
+	objectsModel, inventoryModel, diagnostics := objectAttributes(ctx, objects, nil)
+	if resp.Diagnostics.Append(diagnostics...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), inventoryID(resMap))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("resources"), data.Resources)...)
+	resp.Diagnostics.Append(attributes.setAttributes(ctx, resp.State.SetAttribute)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("objects"), objectsModel)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("inventory"), inventoryModel)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("prune"), true)...)
+}
+
+// migrateFieldManagers migrates fields of live owned by client-side apply to fieldManager, so the next server-side
+// apply doesn't conflict with them. live is returned unchanged if no client-side field manager owns any field.
+func migrateFieldManagers(ctx context.Context, restClient dynamic.ResourceInterface, live *unstructured.Unstructured, fieldManager string) (*unstructured.Unstructured, error) {
+	patch, err := csaupgrade.UpgradeManagedFieldsPatch(live, clientSideFieldManagers, fieldManager)
+	if err != nil || patch == nil {
+		return live, err
+	}
+	return restClient.Patch(ctx, live.GetName(), k8stypes.JSONPatchType, patch, metav1.PatchOptions{})
+}
+
+// importInventory imports the objects labeled with the ID of a kustomize_apply. The kustomization isn't known yet,
+// so the next apply builds it from the configuration, re-applies objects and prunes the ones no longer generated.
+func (r *KustomizeApplyResource) importInventory(ctx context.Context, id string, resp *resource.ImportStateResponse) {
+	resp.Diagnostics.Append(r.discoveryDiagnostics()...)
+
+	labeled, err := r.listLabeledObjects(ctx, id)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to list objects to import", err.Error())
+		return
+	}
+	if len(labeled) == 0 {
+		resp.Diagnostics.AddError(
+			"Failed to find objects to import",
+			fmt.Sprintf("No objects in the cluster are labeled with %s=%s", inventoryIDLabel, id),
+		)
+		return
+	}
+
+	objectsModel, inventoryModel, diagnostics := objectAttributes(ctx, labeled, nil)
+	if resp.Diagnostics.Append(diagnostics...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), id)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("objects"), objectsModel)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("inventory"), inventoryModel)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("prune"), true)...)
+}
+
+// listLabeledObjects returns the objects of every listable kind which are labeled with id, in the order of the apply
+// phases: CustomResourceDefinitions, Namespaces, cluster scoped objects, then namespaced objects.
+func (r *KustomizeApplyResource) listLabeledObjects(ctx context.Context, id string) ([]*unstructured.Unstructured, error) {
+	// Groups which failed discovery are reported by discoveryDiagnostics
+	resourceLists, err := r.discoveryClient.ServerPreferredResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, err
+	}
+
+	var objects []*unstructured.Unstructured
+	for _, resourceList := range resourceLists {
+		groupVersion, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			return nil, err
+		}
+		for _, apiResource := range resourceList.APIResources {
+			// Subresources can't be listed
+			if strings.Contains(apiResource.Name, "/") || !lo.Contains(apiResource.Verbs, "list") {
+				continue
+			}
+			gvr := groupVersion.WithResource(apiResource.Name)
+			list, err := r.dynamicClient.Resource(gvr).List(ctx, metav1.ListOptions{LabelSelector: inventoryIDLabel + "=" + id})
+			if err != nil {
+				if apierrors.IsForbidden(err) || apierrors.IsNotFound(err) || apierrors.IsMethodNotSupported(err) {
+					continue
+				}
+				return nil, fmt.Errorf("failed to list %s: %w", gvr, err)
+			}
+			for i := range list.Items {
+				objects = append(objects, &list.Items[i])
+			}
+		}
+	}
+
+	// The same object may be served by several API groups, e.g. Events
+	objects = lo.UniqBy(objects, func(object *unstructured.Unstructured) k8stypes.UID { return object.GetUID() })
+	sort.SliceStable(objects, func(i, j int) bool {
+		if applyPhase(objects[i]) != applyPhase(objects[j]) {
+			return applyPhase(objects[i]) < applyPhase(objects[j])
+		}
+		return objectName(objects[i]) < objectName(objects[j])
+	})
+	return objects, nil
+}
+
+// applyPhase returns the phase of applyResMap in which object is applied.
+func applyPhase(object *unstructured.Unstructured) int {
+	gvk := object.GroupVersionKind()
+	switch {
+	case gvk.Group == "apiextensions.k8s.io" && gvk.Kind == "CustomResourceDefinition":
+		return 0
+	case gvk.Group == "" && gvk.Kind == "Namespace":
+		return 1
+	case object.GetNamespace() == "":
+		return 2
+	default:
+		return 3
+	}
+}
@@ -0,0 +1,182 @@
+package apply
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery/cached/memory"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestIsInventoryID(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{id: "0123456789abcdef0123456789abcdef01234567", want: true},
+		{id: legacyID, want: false},
+		{id: "0123456789abcdef0123456789abcdef0123456", want: false},
+		{id: "0123456789abcdef0123456789abcdef012345678", want: false},
+		{id: "0123456789ABCDEF0123456789ABCDEF01234567", want: false},
+		{id: "github.com/example/repo//overlays/prod?ref=v1.0", want: false},
+		{id: "./overlays/prod", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			if got := isInventoryID(tt.id); got != tt.want {
+				t.Errorf("isInventoryID(%q) = %v, want %v", tt.id, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyPhase(t *testing.T) {
+	objects, err := decodeManifests(`apiVersion: apps/v1
+kind: Deployment
+metadata: {name: app, namespace: default}
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata: {name: app}
+---
+apiVersion: v1
+kind: Namespace
+metadata: {name: default}
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata: {name: widgets.example.com}
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata: {name: widget, namespace: default}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []int
+	for _, object := range objects {
+		got = append(got, applyPhase(object))
+	}
+	if want := []int{3, 2, 1, 0, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("applyPhase() = %v, want %v", got, want)
+	}
+}
+
+// clientSideApplied is a ConfigMap applied by kubectl apply without --server-side.
+const clientSideApplied = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  namespace: default
+  resourceVersion: "1"
+  annotations:
+    kubectl.kubernetes.io/last-applied-configuration: '{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"config","namespace":"default"},"data":{"key":"value"}}'
+  managedFields:
+  - manager: kubectl-client-side-apply
+    operation: Update
+    apiVersion: v1
+    time: "2023-01-01T00:00:00Z"
+    fieldsType: FieldsV1
+    fieldsV1:
+      f:data:
+        .: {}
+        f:key: {}
+      f:metadata:
+        f:annotations:
+          .: {}
+          f:kubectl.kubernetes.io/last-applied-configuration: {}
+data:
+  key: value
+`
+
+func TestMigrateFieldManagers(t *testing.T) {
+	live := decodeLiveObject(t, clientSideApplied)
+	dynamicClient := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme(), live.DeepCopy())
+	restClient := dynamicClient.Resource(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}).Namespace("default")
+
+	migrated, err := migrateFieldManagers(context.Background(), restClient, live, "kustomize")
+	if err != nil {
+		t.Fatal(err)
+	}
+	managers := map[string]metav1.ManagedFieldsOperationType{}
+	for _, entry := range migrated.GetManagedFields() {
+		managers[entry.Manager] = entry.Operation
+	}
+	if want := map[string]metav1.ManagedFieldsOperationType{"kustomize": metav1.ManagedFieldsOperationApply}; !reflect.DeepEqual(managers, want) {
+		t.Errorf("managers after migration = %v, want %v", managers, want)
+	}
+
+	// Objects already applied server-side are left alone
+	patches := len(dynamicClient.Actions())
+	unchanged, err := migrateFieldManagers(context.Background(), restClient, migrated, "kustomize")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dynamicClient.Actions()) != patches || !reflect.DeepEqual(unchanged, migrated) {
+		t.Errorf("migrateFieldManagers() patched an object without client-side field managers")
+	}
+}
+
+func TestListLabeledObjects(t *testing.T) {
+	const id = "0123456789abcdef0123456789abcdef01234567"
+	labeled := func(manifest string) runtime.Object {
+		object := decodeLiveObject(t, manifest)
+		object.SetLabels(map[string]string{inventoryIDLabel: id})
+		return object
+	}
+	objects := []runtime.Object{
+		labeled("apiVersion: v1\nkind: ConfigMap\nmetadata: {name: config, namespace: app, uid: '1'}"),
+		labeled("apiVersion: v1\nkind: Namespace\nmetadata: {name: app, uid: '2'}"),
+		labeled("apiVersion: apiextensions.k8s.io/v1\nkind: CustomResourceDefinition\nmetadata: {name: widgets.example.com, uid: '3'}"),
+		labeled("apiVersion: rbac.authorization.k8s.io/v1\nkind: ClusterRole\nmetadata: {name: app, uid: '4'}"),
+		decodeLiveObject(t, "apiVersion: v1\nkind: ConfigMap\nmetadata: {name: unlabeled, namespace: app, uid: '5'}"),
+	}
+	listKinds := map[schema.GroupVersionResource]string{
+		{Version: "v1", Resource: "configmaps"}:                                               "ConfigMapList",
+		{Version: "v1", Resource: "namespaces"}:                                               "NamespaceList",
+		{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}: "CustomResourceDefinitionList",
+		{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles"}:         "ClusterRoleList",
+		{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterrolebindings"}:  "ClusterRoleBindingList",
+	}
+	verbs := metav1.Verbs{"get", "list", "watch"}
+	discoveryClient := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: []*metav1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{
+			{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: verbs},
+			{Name: "namespaces", Kind: "Namespace", Verbs: verbs},
+			{Name: "namespaces/status", Kind: "Namespace", Verbs: verbs},
+		}},
+		{GroupVersion: "apiextensions.k8s.io/v1", APIResources: []metav1.APIResource{
+			{Name: "customresourcedefinitions", Kind: "CustomResourceDefinition", Verbs: verbs},
+		}},
+		{GroupVersion: "rbac.authorization.k8s.io/v1", APIResources: []metav1.APIResource{
+			{Name: "clusterroles", Kind: "ClusterRole", Verbs: verbs},
+			{Name: "clusterrolebindings", Kind: "ClusterRoleBinding", Verbs: verbs},
+		}},
+		{GroupVersion: "authorization.k8s.io/v1", APIResources: []metav1.APIResource{
+			{Name: "selfsubjectaccessreviews", Kind: "SelfSubjectAccessReview", Verbs: metav1.Verbs{"create"}},
+		}},
+	}}}
+	r := &KustomizeApplyResource{kubernetesClient: kubernetesClient{
+		dynamicClient:   fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, objects...),
+		discoveryClient: memory.NewMemCacheClient(discoveryClient),
+	}}
+
+	got, err := r.listLabeledObjects(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, object := range got {
+		names = append(names, objectName(object))
+	}
+	want := []string{"CustomResourceDefinition widgets.example.com", "Namespace app", "ClusterRole app", "ConfigMap app/config"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("listLabeledObjects() = %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,110 @@
+package apply
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// InventoryModel identifies a single Kubernetes object applied by kustomize_apply.
+type InventoryModel struct {
+	Group     types.String `tfsdk:"group"`
+	Version   types.String `tfsdk:"version"`
+	Kind      types.String `tfsdk:"kind"`
+	Namespace types.String `tfsdk:"namespace"`
+	Name      types.String `tfsdk:"name"`
+	Uid       types.String `tfsdk:"uid"`
+}
+
+var inventoryAttrTypes = map[string]attr.Type{
+	"group":     types.StringType,
+	"version":   types.StringType,
+	"kind":      types.StringType,
+	"namespace": types.StringType,
+	"name":      types.StringType,
+	"uid":       types.StringType,
+}
+
+var inventoryElementType = types.ObjectType{AttrTypes: inventoryAttrTypes}
+
+func toInventoryModel(object *unstructured.Unstructured) InventoryModel {
+	gvk := object.GroupVersionKind()
+	return InventoryModel{
+		Group:     types.StringValue(gvk.Group),
+		Version:   types.StringValue(gvk.Version),
+		Kind:      types.StringValue(gvk.Kind),
+		Namespace: types.StringValue(object.GetNamespace()),
+		Name:      types.StringValue(object.GetName()),
+		Uid:       types.StringValue(string(object.GetUID())),
+	}
+}
+
+// Unstructured returns a skeleton object carrying only the identity of the inventory entry.
+func (i InventoryModel) Unstructured() *unstructured.Unstructured {
+	object := &unstructured.Unstructured{}
+	object.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   i.Group.ValueString(),
+		Version: i.Version.ValueString(),
+		Kind:    i.Kind.ValueString(),
+	})
+	object.SetNamespace(i.Namespace.ValueString())
+	object.SetName(i.Name.ValueString())
+	return object
+}
+
+// getInventory returns the inventory of data. State written before inventory was added only has objects, the
+// inventory is derived from their API version, kind and metadata then.
+func getInventory(ctx context.Context, data KustomizeApplyModel) ([]InventoryModel, diag.Diagnostics) {
+	var diagnostics diag.Diagnostics
+	var inventory []InventoryModel
+	if !data.Inventory.IsNull() && !data.Inventory.IsUnknown() {
+		diagnostics.Append(data.Inventory.ElementsAs(ctx, &inventory, false)...)
+		return inventory, diagnostics
+	}
+	if data.Objects.IsNull() || data.Objects.IsUnknown() {
+		return inventory, diagnostics
+	}
+
+	var objects []string
+	if diagnostics.Append(data.Objects.ElementsAs(ctx, &objects, false)...); diagnostics.HasError() {
+		return nil, diagnostics
+	}
+	decoded, err := decodeObjects(objects)
+	if err != nil {
+		diagnostics.AddError("Failed to decode objects from state", err.Error())
+		return nil, diagnostics
+	}
+	for _, object := range decoded {
+		inventory = append(inventory, toInventoryModel(object))
+	}
+	return inventory, diagnostics
+}
+
+// objectAttributes returns the objects and inventory attributes for objects. Entries of extra are appended to inventory
+// only, they are objects which are kept in inventory without being applied.
+func objectAttributes(ctx context.Context, objects []*unstructured.Unstructured, extra []InventoryModel) (types.List, types.List, diag.Diagnostics) {
+	var diagnostics diag.Diagnostics
+	var objectsJson []string
+	var inventory []InventoryModel
+	for _, object := range objects {
+		objectJson, err := marshalObject(object)
+		if err != nil {
+			diagnostics.AddError("failed to convert object to JSON", fmt.Sprintf("%s: %s", objectName(object), err.Error()))
+			return types.ListNull(types.StringType), types.ListNull(inventoryElementType), diagnostics
+		}
+		objectsJson = append(objectsJson, string(objectJson))
+		inventory = append(inventory, toInventoryModel(object))
+	}
+	inventory = append(inventory, extra...)
+
+	objectsModel, objectsDiagnostics := types.ListValueFrom(ctx, types.StringType, &objectsJson)
+	inventoryModel, inventoryDiagnostics := types.ListValueFrom(ctx, inventoryElementType, &inventory)
+	diagnostics.Append(objectsDiagnostics...)
+	diagnostics.Append(inventoryDiagnostics...)
+	return objectsModel, inventoryModel, diagnostics
+}
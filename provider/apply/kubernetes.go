@@ -1,61 +1,150 @@
 package apply
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
 	"sigs.k8s.io/kustomize/api/resource"
+
+	"github.com/webzyno/terraform-provider-kustomize/client"
 )
 
-func checkAPIResourceIsPresent(supportedResources []*metav1.APIResourceList, resource *resource.Resource) (*metav1.APIResource, bool) {
-	for _, srList := range supportedResources {
-		if srList == nil {
-			continue
-		}
-		rGvk := resource.GetGvk()
-		if rGvk.ApiVersion() == srList.GroupVersion {
-			for _, sr := range srList.APIResources {
-				if rGvk.Kind == sr.Kind {
-					sr.Group = srList.GroupVersion
-					sr.Kind = srList.Kind
-					return &sr, true
-				}
-			}
-		}
+// kubernetesClient holds the Kubernetes clients and server-side apply defaults of the provider,
+// which are shared by kustomize_apply and kustomize_object.
+type kubernetesClient struct {
+	dynamicClient    dynamic.Interface
+	discoveryClient  discovery.CachedDiscoveryInterface
+	restMapper       *restmapper.DeferredDiscoveryRESTMapper
+	fieldManager     string
+	forceConflicts   bool
+	defaultNamespace string
+}
+
+func newKubernetesClient(clientSet *client.ClientSet) kubernetesClient {
+	return kubernetesClient{
+		dynamicClient:    clientSet.DynamicClient,
+		discoveryClient:  clientSet.DiscoveryClient,
+		restMapper:       clientSet.RESTMapper,
+		fieldManager:     clientSet.FieldManager,
+		forceConflicts:   clientSet.ForceConflicts,
+		defaultNamespace: clientSet.DefaultNamespace,
 	}
-	return nil, false
 }
 
-func getRestClientFromResource(dynamicClient dynamic.Interface, supportedResources []*metav1.APIResourceList, resource *resource.Resource) (dynamic.ResourceInterface, error) {
-	apiResource, exists := checkAPIResourceIsPresent(supportedResources, resource)
-	if !exists {
-		return nil, errors.New("the Kubernetes API server doesn't support this resource")
+// applyOptions returns server-side apply options, attributes of the resource take precedence over provider defaults.
+func (c *kubernetesClient) applyOptions(fieldManager types.String, forceConflicts types.Bool) metav1.ApplyOptions {
+	options := metav1.ApplyOptions{FieldManager: FieldManager, Force: c.forceConflicts}
+	if c.fieldManager != "" {
+		options.FieldManager = c.fieldManager
 	}
+	if fieldManager.ValueString() != "" {
+		options.FieldManager = fieldManager.ValueString()
+	}
+	if !forceConflicts.IsNull() && !forceConflicts.IsUnknown() {
+		options.Force = forceConflicts.ValueBool()
+	}
+	return options
+}
 
-	resourceSchema := schema.GroupVersionResource{Group: apiResource.Group, Version: apiResource.Version, Resource: apiResource.Name}
-	// For core services (ServiceAccount, Service etc) the group is incorrectly parsed.
-	// "v1" should be empty group and "v1" for version
-	if resourceSchema.Group == "v1" && resourceSchema.Version == "" {
-		resourceSchema.Group = ""
-		resourceSchema.Version = "v1"
+func (c *kubernetesClient) applyResource(ctx context.Context, res *resource.Resource, options metav1.ApplyOptions) (*unstructured.Unstructured, error) {
+	restClient, err := c.getRestClientFromResource(res)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rest client from resource: %w", err)
 	}
 
-	restClient := dynamicClient.Resource(resourceSchema)
+	// Convert resource to unstructured
+	unstructured, err := kustomizeResourceToUnstructured(res)
+	if err != nil {
+		return nil, err
+	}
+
+	return restClient.Apply(ctx, unstructured.GetName(), unstructured, options)
+}
+
+func (c *kubernetesClient) getRestClientFromResource(resource *resource.Resource) (dynamic.ResourceInterface, error) {
+	gvk := resource.GetGvk()
+	return c.getRestClient(schema.GroupVersionKind{Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind}, resource.GetNamespace())
+}
+
+func (c *kubernetesClient) getRestClientFromUnstructured(us *unstructured.Unstructured) (dynamic.ResourceInterface, error) {
+	return c.getRestClient(us.GroupVersionKind(), us.GetNamespace())
+}
+
+// getRestClient returns a client for the given kind. Namespaced objects without namespace go to the default namespace
+// of the provider, like kubectl uses the namespace of the current kubeconfig context.
+func (c *kubernetesClient) getRestClient(gvk schema.GroupVersionKind, namespace string) (dynamic.ResourceInterface, error) {
+	mapping, err := c.getRESTMapping(gvk)
+	if err != nil {
+		return nil, err
+	}
 
-	if apiResource.Namespaced {
-		if resource.GetNamespace() == "" {
-			return restClient.Namespace("default"), nil
+	restClient := c.dynamicClient.Resource(mapping.Resource)
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		if namespace == "" {
+			return restClient.Namespace(c.defaultNamespace), nil
 		}
-		return restClient.Namespace(resource.GetNamespace()), nil
+		return restClient.Namespace(namespace), nil
 	}
 
 	return restClient, nil
 }
 
+// getRESTMapping maps a kind to its API resource through the cached discovery. The cache is reset once when the kind
+// is unknown, as the API server may have started serving it since, e.g. after its CRD got established.
+func (c *kubernetesClient) getRESTMapping(gvk schema.GroupVersionKind) (*meta.RESTMapping, error) {
+	mapping, err := c.restMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		c.restMapper.Reset()
+		mapping, err = c.restMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	}
+	if meta.IsNoMatchError(err) {
+		if groupErr, failed := c.getDiscoveryFailures()[gvk.GroupVersion()]; failed {
+			return nil, fmt.Errorf("discovery of API group %s failed: %w", gvk.GroupVersion(), groupErr)
+		}
+		return nil, fmt.Errorf("the Kubernetes API server doesn't support %s: %w", gvk.GroupKind(), err)
+	}
+	return mapping, err
+}
+
+// getDiscoveryFailures returns the API group versions which the API server failed to serve during discovery.
+func (c *kubernetesClient) getDiscoveryFailures() map[schema.GroupVersion]error {
+	_, _, err := c.discoveryClient.ServerGroupsAndResources()
+	var failed *discovery.ErrGroupDiscoveryFailed
+	if errors.As(err, &failed) {
+		return failed.Groups
+	}
+	return nil
+}
+
+// discoveryDiagnostics warns about every API group version which failed discovery.
+// Objects of these groups can't be applied, while the rest of the cluster stays usable.
+func (c *kubernetesClient) discoveryDiagnostics() diag.Diagnostics {
+	var diagnostics diag.Diagnostics
+	failures := c.getDiscoveryFailures()
+	if len(failures) == 0 {
+		return diagnostics
+	}
+	groups := lo.MapToSlice(failures, func(gv schema.GroupVersion, err error) string {
+		return fmt.Sprintf("%s: %s", gv, err.Error())
+	})
+	sort.Strings(groups)
+	diagnostics.AddWarning("Failed to discover some API groups", strings.Join(groups, "\n"))
+	return diagnostics
+}
+
 func kustomizeResourceToUnstructured(resource *resource.Resource) (*unstructured.Unstructured, error) {
 	json, err := resource.MarshalJSON()
 	if err != nil {
@@ -67,3 +156,18 @@ func kustomizeResourceToUnstructured(resource *resource.Resource) (*unstructured
 	}
 	return us, nil
 }
+
+func objectName(object *unstructured.Unstructured) string {
+	return formatObjectName(object.GetKind(), object.GetNamespace(), object.GetName())
+}
+
+func resourceName(resource *resource.Resource) string {
+	return formatObjectName(resource.GetKind(), resource.GetNamespace(), resource.GetName())
+}
+
+func formatObjectName(kind string, namespace string, name string) string {
+	if namespace == "" {
+		return fmt.Sprintf("%s %s", kind, name)
+	}
+	return fmt.Sprintf("%s %s/%s", kind, namespace, name)
+}
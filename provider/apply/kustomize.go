@@ -1,29 +1,68 @@
 package apply
 
 import (
-	"fmt"
+	"context"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/samber/lo"
 	"sigs.k8s.io/kustomize/api/krusty"
-	"sigs.k8s.io/kustomize/api/resmap"
-	"sigs.k8s.io/kustomize/kyaml/yaml"
 
-	"github.com/webzyno/terraform-provider-kustomize/virtfs"
+	"github.com/webzyno/terraform-provider-kustomize/kustomize"
 )
 
-func kustomizeBuild(kustomizer *krusty.Kustomizer, model KustomizeApplyModel) (resmap.ResMap, error) {
-	// Create overlay filesystem and write kustomization.yaml
-	fs, err := virtfs.NewOverlayFS()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create OverlayFS: %w", err)
-	}
-	kustomization := ToKustomization(model)
-	kustomizationContent, err := yaml.Marshal(&kustomization)
+func kustomizeBuild(kustomizer *krusty.Kustomizer, model KustomizeApplyModel) (*kustomize.BuildResult, error) {
+	return kustomize.Build(kustomizer, ToKustomization(model), kustomize.BuildOptions{
+		BaseDir:         model.BaseDir.ValueString(),
+		Path:            model.Path.ValueString(),
+		InlineResources: lo.Map(model.InlineResources, func(manifest types.String, _ int) string { return manifest.ValueString() }),
+		Files:           model.Files,
+	})
+}
+
+// buildAttributes are the attributes of kustomize_apply computed from the result of kustomize build.
+type buildAttributes struct {
+	Yaml        types.String
+	Secrets     types.Map
+	SourceFiles types.List
+	SourceHash  types.String
+}
+
+// newBuildAttributes computes the attributes of a build. Secret values are only kept in the sensitive secrets attribute.
+func newBuildAttributes(ctx context.Context, result *kustomize.BuildResult) (buildAttributes, diag.Diagnostics) {
+	var diagnostics diag.Diagnostics
+	var attributes buildAttributes
+	manifests, secrets, err := kustomize.RedactedYaml(result.ResMap)
 	if err != nil {
-		return nil, fmt.Errorf("unexpected error during marshaling kustomization.yaml: %w", err)
-	}
-	if err := fs.WriteFile(virtfs.KUSTOMIZATION, kustomizationContent); err != nil {
-		return nil, fmt.Errorf("failed to write kustomization.yaml to file system: %w", err)
+		diagnostics.AddError("Failed to convert generated manifests to YAML", err.Error())
+		return attributes, diagnostics
 	}
+	attributes.Yaml = types.StringValue(string(manifests))
+
+	var secretsDiagnostics, sourceDiagnostics diag.Diagnostics
+	attributes.Secrets, secretsDiagnostics = types.MapValueFrom(ctx, types.StringType, secrets)
+	attributes.SourceFiles, sourceDiagnostics = types.ListValueFrom(ctx, types.StringType, result.SourceFiles())
+	attributes.SourceHash = types.StringValue(result.SourceHash())
+	diagnostics.Append(secretsDiagnostics...)
+	diagnostics.Append(sourceDiagnostics...)
+	return attributes, diagnostics
+}
+
+// set copies the attributes to data.
+func (a buildAttributes) set(data *KustomizeApplyModel) {
+	data.Yaml = a.Yaml
+	data.Secrets = a.Secrets
+	data.SourceFiles = a.SourceFiles
+	data.SourceHash = a.SourceHash
+}
 
-	return kustomizer.Run(fs, ".")
+// setAttributes sets the attributes through setAttribute, which is SetAttribute of a plan or state.
+func (a buildAttributes) setAttributes(ctx context.Context, setAttribute func(context.Context, path.Path, interface{}) diag.Diagnostics) diag.Diagnostics {
+	var diagnostics diag.Diagnostics
+	diagnostics.Append(setAttribute(ctx, path.Root("yaml"), a.Yaml)...)
+	diagnostics.Append(setAttribute(ctx, path.Root("secrets"), a.Secrets)...)
+	diagnostics.Append(setAttribute(ctx, path.Root("source_files"), a.SourceFiles)...)
+	diagnostics.Append(setAttribute(ctx, path.Root("source_hash"), a.SourceHash)...)
+	return diagnostics
 }
@@ -1,6 +1,9 @@
 package apply
 
 import (
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/samber/lo"
 	ktypes "sigs.k8s.io/kustomize/api/types"
@@ -11,9 +14,26 @@ import (
 
 type KustomizeApplyModel struct {
 	// Custom fileds
-	Id      types.String `tfsdk:"id"`
-	Yaml    types.String `tfsdk:"yaml"`
-	Objects types.List   `tfsdk:"objects"`
+	Id                types.String      `tfsdk:"id"`
+	Yaml              types.String      `tfsdk:"yaml"`
+	Path              types.String      `tfsdk:"path"`
+	BaseDir           types.String      `tfsdk:"base_dir"`
+	InlineResources   []types.String    `tfsdk:"inline_resources"`
+	Files             map[string]string `tfsdk:"files"`
+	Secrets           types.Map         `tfsdk:"secrets"`
+	SourceFiles       types.List        `tfsdk:"source_files"`
+	SourceHash        types.String      `tfsdk:"source_hash"`
+	Objects           types.List        `tfsdk:"objects"`
+	Inventory         types.List        `tfsdk:"inventory"`
+	Prune             types.Bool        `tfsdk:"prune"`
+	PlanDryRun        types.Bool        `tfsdk:"plan_dry_run"`
+	FieldManager      types.String      `tfsdk:"field_manager"`
+	ForceConflicts    types.Bool        `tfsdk:"force_conflicts"`
+	DeletePropagation types.String      `tfsdk:"delete_propagation"`
+	WaitForDeletion   types.Bool        `tfsdk:"wait_for_deletion"`
+	Parallelism       types.Int64       `tfsdk:"parallelism"`
+	Wait              *WaitModel        `tfsdk:"wait"`
+	Timeouts          timeouts.Value    `tfsdk:"timeouts"`
 
 	CommonAnnotations  map[string]string           `tfsdk:"common_annotations"`
 	BuildMetadata      []types.String              `tfsdk:"build_metadata"`
@@ -41,6 +61,18 @@ type KustomizeApplyModel struct {
 	Validators         []types.String              `tfsdk:"validators"`
 }
 
+type WaitModel struct {
+	Timeout types.String `tfsdk:"timeout"`
+}
+
+// GetTimeout returns the configured wait timeout, or the default when it or wait isn't set.
+func (w *WaitModel) GetTimeout() (time.Duration, error) {
+	if w == nil || w.Timeout.IsNull() || w.Timeout.ValueString() == "" {
+		return defaultWaitTimeout, nil
+	}
+	return time.ParseDuration(w.Timeout.ValueString())
+}
+
 func ToKustomization(model KustomizeApplyModel) ktypes.Kustomization {
 	return ktypes.Kustomization{
 		TypeMeta: ktypes.TypeMeta{
@@ -0,0 +1,253 @@
+package apply
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/samber/lo"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kprovider "sigs.k8s.io/kustomize/api/provider"
+	kresource "sigs.k8s.io/kustomize/api/resource"
+
+	"github.com/webzyno/terraform-provider-kustomize/client"
+	"github.com/webzyno/terraform-provider-kustomize/kustomize"
+)
+
+// KustomizeObjectResource manages a single object, so every object of a build gets its own plan with for_each.
+type KustomizeObjectResource struct {
+	kubernetesClient
+}
+
+func NewKustomizeObject() resource.Resource {
+	return &KustomizeObjectResource{}
+}
+
+func (r *KustomizeObjectResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_object"
+}
+
+func (r *KustomizeObjectResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = kustomizeObjectSchema
+}
+
+func (r *KustomizeObjectResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	clientSet, ok := req.ProviderData.(*client.ClientSet)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *client.ClientSet, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+	r.kubernetesClient = newKubernetesClient(clientSet)
+}
+
+func (r *KustomizeObjectResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data KustomizeObjectModel
+	if resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	if resp.Diagnostics.Append(r.apply(ctx, &data)...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *KustomizeObjectResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data KustomizeObjectModel
+	if resp.Diagnostics.Append(req.State.Get(ctx, &data)...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	res, err := parseManifest(data.Manifest.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid manifest", err.Error())
+		return
+	}
+	restClient, err := r.getRestClientFromResource(res)
+	// Objects whose kind is no longer served, e.g. because their CRD was deleted, are gone as well
+	if meta.IsNoMatchError(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to create rest client from resource", err.Error())
+		return
+	}
+
+	// Remove the object from state if it was deleted outside of Terraform, so it gets created again
+	live, err := restClient.Get(ctx, res.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("Failed to get object", err.Error())
+		return
+	}
+	liveJson, err := marshalObject(live)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to convert live object to JSON", err.Error())
+		return
+	}
+	data.Object = types.StringValue(string(liveJson))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *KustomizeObjectResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to plan on destroy
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+	var plan KustomizeObjectModel
+	if resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...); resp.Diagnostics.HasError() {
+		return
+	}
+	if plan.Manifest.IsUnknown() {
+		return
+	}
+	planned, err := parseManifest(plan.Manifest.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid manifest", err.Error())
+		return
+	}
+	if err := checkRedacted(planned); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("manifest"), "Invalid manifest", err.Error())
+		return
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("id"), kustomize.ResourceID(planned))...)
+
+	// Nothing to compare with on create
+	if req.State.Raw.IsNull() {
+		return
+	}
+	var state KustomizeObjectModel
+	if resp.Diagnostics.Append(req.State.Get(ctx, &state)...); resp.Diagnostics.HasError() {
+		return
+	}
+	current, err := parseManifest(state.Manifest.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid manifest in state", err.Error())
+		return
+	}
+
+	// A different object can't be applied in place, the previous one must be deleted
+	if planned.GetGvk().Group != current.GetGvk().Group || planned.GetKind() != current.GetKind() ||
+		planned.GetNamespace() != current.GetNamespace() || planned.GetName() != current.GetName() {
+		resp.RequiresReplace = append(resp.RequiresReplace, path.Root("manifest"))
+		return
+	}
+
+	// Re-apply the unchanged manifest if the refreshed object drifted from it
+	if !plan.Manifest.Equal(state.Manifest) || state.Object.IsNull() {
+		return
+	}
+	desired, err := decodeManifests(state.Manifest.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to decode manifest from state", err.Error())
+		return
+	}
+	live, err := decodeObjects([]string{state.Object.ValueString()})
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to decode object from state", err.Error())
+		return
+	}
+	// The object in state is redacted, so Secret values are compared in redacted form
+	desired = lo.Map(desired, func(object *unstructured.Unstructured, _ int) *unstructured.Unstructured { return redactObject(object) })
+	if hasDrift(desired, live, r.applyOptions(state.FieldManager, state.ForceConflicts).FieldManager) {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("object"), types.StringUnknown())...)
+	}
+}
+
+func (r *KustomizeObjectResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data KustomizeObjectModel
+	if resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	if resp.Diagnostics.Append(r.apply(ctx, &data)...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *KustomizeObjectResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data KustomizeObjectModel
+	if resp.Diagnostics.Append(req.State.Get(ctx, &data)...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	res, err := parseManifest(data.Manifest.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid manifest", err.Error())
+		return
+	}
+	restClient, err := r.getRestClientFromResource(res)
+	if meta.IsNoMatchError(err) {
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to create rest client from resource", err.Error())
+		return
+	}
+	if err := restClient.Delete(ctx, res.GetName(), metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		resp.Diagnostics.AddError("Failed to delete object", fmt.Sprintf("%s: %s", resourceName(res), err.Error()))
+	}
+}
+
+// apply server-side applies the manifest of data and saves the applied object to it.
+func (r *KustomizeObjectResource) apply(ctx context.Context, data *KustomizeObjectModel) diag.Diagnostics {
+	var diagnostics diag.Diagnostics
+	res, err := parseManifest(data.Manifest.ValueString())
+	if err != nil {
+		diagnostics.AddError("Invalid manifest", err.Error())
+		return diagnostics
+	}
+	if err := checkRedacted(res); err != nil {
+		diagnostics.AddAttributeError(path.Root("manifest"), "Invalid manifest", err.Error())
+		return diagnostics
+	}
+
+	object, err := r.applyResource(ctx, res, r.applyOptions(data.FieldManager, data.ForceConflicts))
+	if err != nil {
+		diagnostics.AddError("Failed to apply object", fmt.Sprintf("%s: %s", resourceName(res), err.Error()))
+		return diagnostics
+	}
+	objectJson, err := marshalObject(object)
+	if err != nil {
+		diagnostics.AddError("failed to convert applied object to JSON", err.Error())
+		return diagnostics
+	}
+
+	data.Id = types.StringValue(kustomize.ResourceID(res))
+	data.Object = types.StringValue(string(objectJson))
+	return diagnostics
+}
+
+// parseManifest parses a manifest holding exactly one object in yaml or JSON format.
+func parseManifest(manifest string) (*kresource.Resource, error) {
+	resources, err := kprovider.NewDefaultDepProvider().GetResourceFactory().SliceFromBytes([]byte(manifest))
+	if err != nil {
+		return nil, err
+	}
+	if len(resources) != 1 {
+		return nil, fmt.Errorf("expected exactly one object in manifest, found %d", len(resources))
+	}
+	return resources[0], nil
+}
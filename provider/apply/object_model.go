@@ -0,0 +1,11 @@
+package apply
+
+import "github.com/hashicorp/terraform-plugin-framework/types"
+
+type KustomizeObjectModel struct {
+	Id             types.String `tfsdk:"id"`
+	Manifest       types.String `tfsdk:"manifest"`
+	FieldManager   types.String `tfsdk:"field_manager"`
+	ForceConflicts types.Bool   `tfsdk:"force_conflicts"`
+	Object         types.String `tfsdk:"object"`
+}
@@ -0,0 +1,31 @@
+package apply
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+)
+
+var kustomizeObjectSchema = schema.Schema{
+	Description: "Server-side apply a single Kubernetes object, e.g. one of the `manifests` of `kustomize_build`. Secret values are redacted in `manifests`, use `secrets` of `kustomize_build` for Secrets.",
+	Attributes: map[string]schema.Attribute{
+		"id": schema.StringAttribute{
+			Computed:    true,
+			Description: "The API version, kind, namespace and name of the object, e.g. `apps/v1/Deployment/ns/name`.",
+		},
+		"manifest": schema.StringAttribute{
+			Required:    true,
+			Description: "The Kubernetes object in yaml or JSON format. The object is replaced when its group, kind, namespace or name changes.",
+		},
+		"field_manager": schema.StringAttribute{
+			Optional:    true,
+			Description: "The field manager name used for server-side apply. Defaults to the provider `field_manager`, which defaults to `terraform-provider-kustomize`.",
+		},
+		"force_conflicts": schema.BoolAttribute{
+			Optional:    true,
+			Description: "Force server-side apply to take ownership of fields managed by other field managers. Defaults to the provider `force_conflicts`.",
+		},
+		"object": schema.StringAttribute{
+			Computed:    true,
+			Description: "The applied Kubernetes object in JSON format. Values of Secret `data` and `stringData` are redacted.",
+		},
+	},
+}
@@ -0,0 +1,133 @@
+package apply
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// Number of objects applied concurrently when neither the resource nor the provider sets parallelism
+const defaultParallelism = 10
+
+func isCRD(res *resource.Resource) bool {
+	return res.GetGvk().Group == "apiextensions.k8s.io" && res.GetKind() == "CustomResourceDefinition"
+}
+
+func isNamespace(res *resource.Resource) bool {
+	return res.GetGvk().Group == "" && res.GetKind() == "Namespace"
+}
+
+// applyResMap server-side applies every resource of resMap in phases, so that one build can install an operator
+// together with its custom resources:
+//  1. CustomResourceDefinitions, then wait until they are established
+//  2. Namespaces
+//  3. Cluster scoped objects
+//  4. Namespaced objects
+//
+// Objects within a phase are applied concurrently by up to parallelism workers. Discovery is refreshed after CRDs are
+// established, waiting for them takes at most crdTimeout. The applied objects are returned in resMap order within each
+// phase.
+// A failing object doesn't stop the other objects of its phase, but later phases are skipped. Every failed object gets
+// its own error diagnostic, and the objects applied so far are returned along with them.
+func (r *KustomizeApplyResource) applyResMap(ctx context.Context, resMap resmap.ResMap, options metav1.ApplyOptions, parallelism int, crdTimeout time.Duration) ([]*unstructured.Unstructured, diag.Diagnostics) {
+	var diagnostics diag.Diagnostics
+	all := resMap.Resources()
+	crds := lo.Filter(all, func(res *resource.Resource, _ int) bool { return isCRD(res) })
+	namespaces := lo.Filter(all, func(res *resource.Resource, _ int) bool { return isNamespace(res) })
+	others := lo.Filter(all, func(res *resource.Resource, _ int) bool { return !isCRD(res) && !isNamespace(res) })
+
+	diagnostics.Append(r.discoveryDiagnostics()...)
+
+	var applied []*unstructured.Unstructured
+	appliedResources := map[*resource.Resource]bool{}
+	applyPhase := func(phase []*resource.Resource) bool {
+		objects, errs := r.applyResources(ctx, phase, options, parallelism)
+		succeeded := true
+		for i, res := range phase {
+			if errs[i] != nil {
+				succeeded = false
+				continue
+			}
+			applied = append(applied, objects[i])
+			appliedResources[res] = true
+		}
+		if succeeded {
+			return true
+		}
+
+		if ctx.Err() != nil {
+			diagnostics.AddError("Timed out applying objects", pendingResourcesError("applying", lo.Filter(all, func(res *resource.Resource, _ int) bool {
+				return !appliedResources[res]
+			})))
+			return false
+		}
+		for i, res := range phase {
+			if errs[i] != nil {
+				diagnostics.AddError("Failed to apply object", fmt.Sprintf("%s: %s", resourceName(res), errs[i].Error()))
+			}
+		}
+		return false
+	}
+
+	// CRDs must be established before discovery serves their custom resources
+	if !applyPhase(crds) {
+		return applied, diagnostics
+	}
+	if len(crds) > 0 {
+		if err := r.waitForObjects(ctx, applied, crdTimeout); err != nil {
+			diagnostics.AddError("Failed waiting for CustomResourceDefinitions to become established", err.Error())
+			return applied, diagnostics
+		}
+		r.restMapper.Reset()
+	}
+
+	if !applyPhase(namespaces) {
+		return applied, diagnostics
+	}
+
+	// Unsupported objects are applied with namespaced objects where they fail with a descriptive error
+	clusterScoped := lo.Filter(others, func(res *resource.Resource, _ int) bool {
+		gvk := res.GetGvk()
+		mapping, err := r.getRESTMapping(schema.GroupVersionKind{Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind})
+		return err == nil && mapping.Scope.Name() == meta.RESTScopeNameRoot
+	})
+	namespaced := lo.Filter(others, func(res *resource.Resource, _ int) bool { return !lo.Contains(clusterScoped, res) })
+	if !applyPhase(clusterScoped) {
+		return applied, diagnostics
+	}
+	applyPhase(namespaced)
+	return applied, diagnostics
+}
+
+// applyResources applies every resource with a bounded number of workers.
+// Objects and errors are indexed like resList, so results don't depend on scheduling.
+func (r *KustomizeApplyResource) applyResources(ctx context.Context, resList []*resource.Resource, options metav1.ApplyOptions, parallelism int) ([]*unstructured.Unstructured, []error) {
+	objects := make([]*unstructured.Unstructured, len(resList))
+	errs := make([]error, len(resList))
+
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, lo.Max([]int{parallelism, 1}))
+	for i, res := range resList {
+		wg.Add(1)
+		workers <- struct{}{}
+		go func(i int, res *resource.Resource) {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
+			objects[i], errs[i] = r.applyResource(ctx, res, options)
+		}(i, res)
+	}
+	wg.Wait()
+	return objects, errs
+}
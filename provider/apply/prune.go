@@ -0,0 +1,30 @@
+package apply
+
+import (
+	"context"
+
+	"github.com/samber/lo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// pruneObjects deletes objects recorded in prior inventory that are no longer part of the applied objects.
+// Objects are deleted in reverse order of prior inventory, which is the reverse of the order they were applied in.
+func (r *KustomizeApplyResource) pruneObjects(ctx context.Context, prior []InventoryModel, applied []*unstructured.Unstructured, options metav1.DeleteOptions, wait bool) error {
+	return r.deleteObjects(ctx, lo.Reverse(notApplied(prior, applied)), options, wait)
+}
+
+// notApplied returns entries of prior inventory which don't match any of the applied objects.
+func notApplied(prior []InventoryModel, applied []*unstructured.Unstructured) []InventoryModel {
+	return lo.Filter(prior, func(item InventoryModel, _ int) bool {
+		return !lo.ContainsBy(applied, func(a *unstructured.Unstructured) bool { return isSameObject(item.Unstructured(), a) })
+	})
+}
+
+// notDesired returns entries of inventory which don't match any of the desired manifests. They are left over from a
+// prune or an apply which failed, and are pruned by the next apply.
+func notDesired(inventory []InventoryModel, desired []*unstructured.Unstructured) []InventoryModel {
+	return lo.Filter(inventory, func(item InventoryModel, _ int) bool {
+		return !lo.ContainsBy(desired, func(d *unstructured.Unstructured) bool { return isSameObject(d, item.Unstructured()) })
+	})
+}
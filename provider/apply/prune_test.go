@@ -0,0 +1,196 @@
+package apply
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func inventoryItem(group string, version string, kind string, namespace string, name string) InventoryModel {
+	return InventoryModel{
+		Group:     types.StringValue(group),
+		Version:   types.StringValue(version),
+		Kind:      types.StringValue(kind),
+		Namespace: types.StringValue(namespace),
+		Name:      types.StringValue(name),
+		Uid:       types.StringValue(""),
+	}
+}
+
+func TestNotDesired(t *testing.T) {
+	desired, err := decodeManifests(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: default
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		inventory []InventoryModel
+		want      []string
+	}{
+		{
+			name: "all desired",
+			inventory: []InventoryModel{
+				inventoryItem("", "v1", "ConfigMap", "default", "config"),
+				inventoryItem("apps", "v1", "Deployment", "default", "app"),
+			},
+		},
+		{
+			name: "left over after failed prune",
+			inventory: []InventoryModel{
+				inventoryItem("", "v1", "ConfigMap", "default", "config"),
+				inventoryItem("apps", "v1", "Deployment", "default", "app"),
+				inventoryItem("", "v1", "Service", "default", "app"),
+			},
+			want: []string{"Service/app"},
+		},
+		{
+			name: "namespace changed",
+			inventory: []InventoryModel{
+				inventoryItem("", "v1", "ConfigMap", "default", "config"),
+				inventoryItem("apps", "v1", "Deployment", "other", "app"),
+			},
+			want: []string{"Deployment/app"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, item := range notDesired(tt.inventory, desired) {
+				got = append(got, item.Kind.ValueString()+"/"+item.Name.ValueString())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("notDesired() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotApplied(t *testing.T) {
+	tests := []struct {
+		name    string
+		prior   []InventoryModel
+		applied string
+		want    []string
+	}{
+		{
+			name:  "renamed through name_prefix",
+			prior: []InventoryModel{inventoryItem("", "v1", "ConfigMap", "default", "config")},
+			applied: `apiVersion: v1
+kind: ConfigMap
+metadata: {name: prefix-config, namespace: default}`,
+			want: []string{"ConfigMap/config"},
+		},
+		{
+			name:  "renamed through name_suffix",
+			prior: []InventoryModel{inventoryItem("apps", "v1", "Deployment", "default", "app")},
+			applied: `apiVersion: apps/v1
+kind: Deployment
+metadata: {name: app-suffix, namespace: default}`,
+			want: []string{"Deployment/app"},
+		},
+		{
+			name:  "api version changed within group",
+			prior: []InventoryModel{inventoryItem("autoscaling", "v2beta2", "HorizontalPodAutoscaler", "default", "app")},
+			applied: `apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata: {name: app, namespace: default}`,
+		},
+		{
+			name:  "kind changed across groups",
+			prior: []InventoryModel{inventoryItem("apps", "v1", "Deployment", "default", "app")},
+			applied: `apiVersion: argoproj.io/v1alpha1
+kind: Rollout
+metadata: {name: app, namespace: default}`,
+			want: []string{"Deployment/app"},
+		},
+		{
+			name:  "namespace changed",
+			prior: []InventoryModel{inventoryItem("", "v1", "ConfigMap", "old", "config")},
+			applied: `apiVersion: v1
+kind: ConfigMap
+metadata: {name: config, namespace: new}`,
+			want: []string{"ConfigMap/config"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			applied, err := decodeManifests(tt.applied)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, item := range notApplied(tt.prior, applied) {
+				got = append(got, item.Kind.ValueString()+"/"+item.Name.ValueString())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("notApplied() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsSameObject(t *testing.T) {
+	tests := []struct {
+		name    string
+		desired string
+		live    string
+		want    bool
+	}{
+		{
+			name:    "namespace defaulted by the API server",
+			desired: "apiVersion: v1\nkind: ConfigMap\nmetadata: {name: config}",
+			live:    "apiVersion: v1\nkind: ConfigMap\nmetadata: {name: config, namespace: default}",
+			want:    true,
+		},
+		{
+			name:    "different namespace",
+			desired: "apiVersion: v1\nkind: ConfigMap\nmetadata: {name: config, namespace: a}",
+			live:    "apiVersion: v1\nkind: ConfigMap\nmetadata: {name: config, namespace: b}",
+			want:    false,
+		},
+		{
+			name:    "api version changed within group",
+			desired: "apiVersion: autoscaling/v2\nkind: HorizontalPodAutoscaler\nmetadata: {name: app}",
+			live:    "apiVersion: autoscaling/v2beta2\nkind: HorizontalPodAutoscaler\nmetadata: {name: app}",
+			want:    true,
+		},
+		{
+			name:    "same kind in another group",
+			desired: "apiVersion: networking.k8s.io/v1\nkind: Ingress\nmetadata: {name: app}",
+			live:    "apiVersion: extensions/v1beta1\nkind: Ingress\nmetadata: {name: app}",
+			want:    false,
+		},
+		{
+			name:    "renamed",
+			desired: "apiVersion: v1\nkind: ConfigMap\nmetadata: {name: prefix-config}",
+			live:    "apiVersion: v1\nkind: ConfigMap\nmetadata: {name: config}",
+			want:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desired, err := decodeManifests(tt.desired)
+			if err != nil {
+				t.Fatal(err)
+			}
+			live, err := decodeManifests(tt.live)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := isSameObject(desired[0], live[0]); got != tt.want {
+				t.Errorf("isSameObject() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package apply
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kresource "sigs.k8s.io/kustomize/api/resource"
+
+	"github.com/webzyno/terraform-provider-kustomize/kustomize"
+)
+
+// Annotation in which kubectl apply keeps the last applied manifest, including Secret values in plain text
+const lastAppliedConfigAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// redactObject returns a copy of object with the values of Secret data and stringData redacted, as well as the last
+// applied configuration of kubectl which holds them too.
+func redactObject(object *unstructured.Unstructured) *unstructured.Unstructured {
+	redacted := object.DeepCopy()
+	gvk := redacted.GroupVersionKind()
+	if !kustomize.IsSecret(gvk.Group, gvk.Kind) {
+		return redacted
+	}
+	if annotations := redacted.GetAnnotations(); annotations[lastAppliedConfigAnnotation] != "" {
+		annotations[lastAppliedConfigAnnotation] = kustomize.RedactedValue
+		redacted.SetAnnotations(annotations)
+	}
+	for _, field := range kustomize.SecretFields {
+		values, found, _ := unstructured.NestedMap(redacted.Object, field)
+		if !found {
+			continue
+		}
+		for key := range values {
+			values[key] = kustomize.RedactedValue
+		}
+		_ = unstructured.SetNestedMap(redacted.Object, values, field)
+	}
+	return redacted
+}
+
+// marshalObject converts object to JSON for the objects attribute, which isn't sensitive.
+func marshalObject(object *unstructured.Unstructured) ([]byte, error) {
+	return redactObject(object).MarshalJSON()
+}
+
+// checkRedacted returns an error if res is a Secret with redacted values, e.g. one of the manifests of kustomize_build,
+// which would apply the placeholder instead of the real values.
+func checkRedacted(res *kresource.Resource) error {
+	if !kustomize.IsSecret(res.GetGvk().Group, res.GetKind()) {
+		return nil
+	}
+	object, err := res.Map()
+	if err != nil {
+		return err
+	}
+	for _, field := range kustomize.SecretFields {
+		values, _, _ := unstructured.NestedMap(object, field)
+		for key, value := range values {
+			if value == kustomize.RedactedValue {
+				return fmt.Errorf("%s.%s of Secret %s is redacted, use the manifest from secrets of kustomize_build instead of manifests", field, key, res.GetName())
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package apply
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/webzyno/terraform-provider-kustomize/kustomize"
+)
+
+func TestRedactObject(t *testing.T) {
+	objects, err := decodeManifests(`apiVersion: v1
+kind: Secret
+metadata:
+  name: credentials
+  annotations:
+    kubectl.kubernetes.io/last-applied-configuration: '{"data":{"password":"c2VjcmV0"}}'
+data:
+  password: c2VjcmV0
+stringData:
+  token: plain-token
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  annotations:
+    kubectl.kubernetes.io/last-applied-configuration: '{"data":{"password":"not-a-secret"}}'
+data:
+  password: not-a-secret
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, configMap := objects[0], objects[1]
+
+	redacted := redactObject(secret)
+	for _, field := range [][]string{{"data", "password"}, {"stringData", "token"}} {
+		if value, _, _ := unstructured.NestedString(redacted.Object, field...); value != kustomize.RedactedValue {
+			t.Errorf("%v = %q, want %q", field, value, kustomize.RedactedValue)
+		}
+	}
+	if value := redacted.GetAnnotations()[lastAppliedConfigAnnotation]; value != kustomize.RedactedValue {
+		t.Errorf("last applied configuration = %q, want %q", value, kustomize.RedactedValue)
+	}
+	if value, _, _ := unstructured.NestedString(secret.Object, "data", "password"); value != "c2VjcmV0" {
+		t.Errorf("original data.password = %q, want object untouched", value)
+	}
+
+	if redacted := redactObject(configMap); redacted.GetAnnotations()[lastAppliedConfigAnnotation] != configMap.GetAnnotations()[lastAppliedConfigAnnotation] {
+		t.Errorf("ConfigMap last applied configuration was redacted")
+	}
+	if value, _, _ := unstructured.NestedString(redactObject(configMap).Object, "data", "password"); value != "not-a-secret" {
+		t.Errorf("ConfigMap data.password = %q, want it untouched", value)
+	}
+}
+
+func TestCheckRedacted(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		wantErr  bool
+	}{
+		{name: "secret data", manifest: "apiVersion: v1\nkind: Secret\nmetadata:\n  name: s\ndata:\n  password: c2VjcmV0\n"},
+		{name: "redacted data", manifest: "apiVersion: v1\nkind: Secret\nmetadata:\n  name: s\ndata:\n  password: (sensitive value)\n", wantErr: true},
+		{name: "redacted stringData", manifest: "apiVersion: v1\nkind: Secret\nmetadata:\n  name: s\nstringData:\n  password: (sensitive value)\n", wantErr: true},
+		{name: "configmap", manifest: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: c\ndata:\n  password: (sensitive value)\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := parseManifest(tt.manifest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := checkRedacted(res); (err != nil) != tt.wantErr {
+				t.Errorf("checkRedacted() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
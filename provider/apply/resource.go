@@ -4,12 +4,14 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/samber/lo"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/discovery"
-	"k8s.io/client-go/dynamic"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/kustomize/api/krusty"
 
 	"github.com/webzyno/terraform-provider-kustomize/client"
@@ -18,9 +20,9 @@ import (
 const FieldManager = "terraform-provider-kustomize"
 
 type KustomizeApplyResource struct {
-	kustomizer      *krusty.Kustomizer
-	dynamicClient   dynamic.Interface
-	discoveryClient discovery.DiscoveryInterface
+	kubernetesClient
+	kustomizer  *krusty.Kustomizer
+	parallelism int
 }
 
 func NewKustomizeApply() resource.Resource {
@@ -50,9 +52,9 @@ func (r *KustomizeApplyResource) Configure(_ context.Context, req resource.Confi
 		)
 		return
 	}
+	r.kubernetesClient = newKubernetesClient(clientSet)
 	r.kustomizer = clientSet.Kustomizer
-	r.dynamicClient = clientSet.DynamicClient
-	r.discoveryClient = clientSet.DiscoveryClient
+	r.parallelism = clientSet.Parallelism
 }
 
 func (r *KustomizeApplyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -61,11 +63,15 @@ func (r *KustomizeApplyResource) Create(ctx context.Context, req resource.Create
 	if resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...); resp.Diagnostics.HasError() {
 		return
 	}
-	// Set id for test purpose
-	data.Id = types.StringValue("test")
+	createTimeout, diagnostics := data.Timeouts.Create(ctx, defaultCreateTimeout)
+	if resp.Diagnostics.Append(diagnostics...); resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
 
 	// Run kustomize build and save resmap in yaml
-	resMap, err := kustomizeBuild(r.kustomizer, data)
+	result, err := kustomizeBuild(r.kustomizer, data)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to run kustomize build",
@@ -73,52 +79,88 @@ func (r *KustomizeApplyResource) Create(ctx context.Context, req resource.Create
 		)
 		return
 	}
-	manifests, err := resMap.AsYaml()
+	resMap := result.ResMap
+	attributes, diagnostics := newBuildAttributes(ctx, result)
+	if resp.Diagnostics.Append(diagnostics...); resp.Diagnostics.HasError() {
+		return
+	}
+	attributes.set(&data)
+	data.Id = types.StringValue(inventoryID(resMap))
+	if err := labelResources(resMap, data.Id.ValueString()); err != nil {
+		resp.Diagnostics.AddError("Failed to label generated objects", err.Error())
+		return
+	}
+
+	waitTimeout, err := data.Wait.GetTimeout()
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to convert generated manifests to YAML", err.Error())
+		resp.Diagnostics.AddError("Invalid wait timeout", err.Error())
 		return
 	}
-	data.Yaml = types.StringValue(string(manifests))
 
-	// Get server supported resources
-	_, resources, err := r.discoveryClient.ServerGroupsAndResources()
-	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
-		resp.Diagnostics.AddError("Failed to get server groups and resources", err.Error())
+	// Apply objects in phases, CRDs and Namespaces first. Objects which succeeded are saved to state even if others failed.
+	applied, applyDiagnostics := r.applyResMap(ctx, resMap, r.getApplyOptions(data), r.getParallelism(data), waitTimeout)
+	data.Objects, data.Inventory, diagnostics = objectAttributes(ctx, applied, nil)
+	if resp.Diagnostics.Append(diagnostics...); resp.Diagnostics.HasError() {
 		return
 	}
 
-	var objects []string
-	for _, res := range resMap.Resources() {
-		restClient, err := getRestClientFromResource(r.dynamicClient, resources, res)
-		if err != nil {
-			resp.Diagnostics.AddError("Failed to create rest client from resource", err.Error())
-			return
+	// Objects which failed to apply are left out of state. Terraform taints the resource, so the next apply replaces it,
+	// deleting the objects which did succeed first, unless the resource is untainted. This is documented on the resource.
+	if applyDiagnostics.HasError() {
+		resp.Diagnostics.Append(applyDiagnostics...)
+		if len(applied) > 0 {
+			resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 		}
+		return
+	}
 
-		// Convert resource to unstructured
-		unstructured, err := kustomizeResourceToUnstructured(res)
-		if err != nil {
-			resp.Diagnostics.AddError("Failed to convert Kustomize resource to unstructured", err.Error())
-			return
+	// Wait for applied objects to become ready, state is saved either way so a failed rollout taints the resource
+	if data.Wait != nil {
+		if err := r.waitForObjects(ctx, applied, waitTimeout); err != nil {
+			resp.Diagnostics.AddError("Failed waiting for objects to become ready", err.Error())
 		}
+	}
 
-		createdUnstructured, err := restClient.Apply(ctx, unstructured.GetName(), unstructured, metav1.ApplyOptions{FieldManager: FieldManager})
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *KustomizeApplyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data KustomizeApplyModel
+	if resp.Diagnostics.Append(req.State.Get(ctx, &data)...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Refresh every object recorded in inventory from the cluster
+	inventory, diagnostics := getInventory(ctx, data)
+	if resp.Diagnostics.Append(diagnostics...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	var liveObjects []*unstructured.Unstructured
+	for _, item := range inventory {
+		restClient, err := r.getRestClientFromUnstructured(item.Unstructured())
+		// Objects whose kind is no longer served, e.g. because their CRD was deleted, are gone as well
+		if meta.IsNoMatchError(err) {
+			continue
+		}
 		if err != nil {
-			resp.Diagnostics.AddError("Failed to create object", err.Error())
+			resp.Diagnostics.AddError("Failed to create rest client from inventory", err.Error())
 			return
 		}
-		createdUsJson, err := createdUnstructured.MarshalJSON()
+
+		liveUnstructured, err := restClient.Get(ctx, item.Name.ValueString(), metav1.GetOptions{})
 		if err != nil {
-			resp.Diagnostics.AddError("failed to convert created object to JSON", err.Error())
+			// Drop objects deleted outside of Terraform so they are recreated by the next apply
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			resp.Diagnostics.AddError("Failed to get object", err.Error())
 			return
 		}
-
-		objects = append(objects, string(createdUsJson))
+		liveObjects = append(liveObjects, liveUnstructured)
 	}
 
-	// Set objects to model
-	objectsModel, diagnostics := types.ListValueFrom(ctx, types.StringType, &objects)
-	data.Objects = objectsModel
+	data.Objects, data.Inventory, diagnostics = objectAttributes(ctx, liveObjects, nil)
 	if resp.Diagnostics.Append(diagnostics...); resp.Diagnostics.HasError() {
 		return
 	}
@@ -128,87 +170,148 @@ func (r *KustomizeApplyResource) Create(ctx context.Context, req resource.Create
 	}
 }
 
-func (r *KustomizeApplyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	var data KustomizeApplyModel
-	if resp.Diagnostics.Append(req.State.Get(ctx, &data)...); resp.Diagnostics.HasError() {
-		return
-	}
-	data.Id = types.StringValue("test")
-
-	// Run kustomize build and save resmap in yaml
-	/*resMap, err := kustomizeBuild(r.kustomizer, data)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Failed to run kustomize build",
-			err.Error(),
-		)
-		return
-	}
-	manifests, err := resMap.AsYaml()
-	if err != nil {
-		resp.Diagnostics.AddError("Failed to convert generated manifests to YAML", err.Error())
-		return
-	}
-	data.Yaml = types.StringValue(string(manifests))
-
-	// Get server supported resources
-	_, resources, err := r.discoveryClient.ServerGroupsAndResources()
-	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
-		resp.Diagnostics.AddError("Failed to get server groups and resources", err.Error())
+func (r *KustomizeApplyResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to plan on destroy
+	if req.Plan.Raw.IsNull() {
 		return
 	}
 
-	var objects []string
-	for _, res := range resMap.Resources() {
-		restClient, err := getRestClientFromResource(r.dynamicClient, resources, res)
-		if err != nil {
-			resp.Diagnostics.AddError("Failed to create rest client from resource", err.Error())
+	// Run kustomize build during plan so changes of remote bases and local files show up as a diff.
+	// The build is skipped when the configuration still contains values known only after apply.
+	built := false
+	var manifests string
+	var plannedSecrets types.Map
+	var config KustomizeApplyModel
+	if r.kustomizer != nil && req.Config.Raw.IsFullyKnown() {
+		if resp.Diagnostics.Append(req.Config.Get(ctx, &config)...); resp.Diagnostics.HasError() {
 			return
 		}
-
-		// Convert resource to unstructured
-		unstructured, err := kustomizeResourceToUnstructured(res)
+		result, err := kustomizeBuild(r.kustomizer, config)
 		if err != nil {
-			resp.Diagnostics.AddError("Failed to convert Kustomize resource to unstructured", err.Error())
+			// The configuration is fully known, so apply would fail with the same error
+			resp.Diagnostics.AddError("Failed to run kustomize build during plan", err.Error())
 			return
 		}
-
-		getUnstructured, err := restClient.Get(ctx, unstructured.GetName(), metav1.GetOptions{})
-		if err != nil {
-			resp.Diagnostics.AddError("Failed to get object", err.Error())
+		resMap := result.ResMap
+		attributes, diagnostics := newBuildAttributes(ctx, result)
+		if resp.Diagnostics.Append(diagnostics...); resp.Diagnostics.HasError() {
 			return
 		}
-		getUnstructuredJson, err := getUnstructured.MarshalJSON()
-		if err != nil {
-			resp.Diagnostics.AddError("failed to convert get object to JSON", err.Error())
-			return
+		built = true
+		manifests = attributes.Yaml.ValueString()
+		plannedSecrets = attributes.Secrets
+		resp.Diagnostics.Append(attributes.setAttributes(ctx, resp.Plan.SetAttribute)...)
+		id := inventoryID(resMap)
+		if !req.State.Raw.IsNull() {
+			var stateID types.String
+			if resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("id"), &stateID)...); resp.Diagnostics.HasError() {
+				return
+			}
+			if stateID.ValueString() != legacyID {
+				id = stateID.ValueString()
+			}
+		}
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("id"), id)...)
+
+		// Surface live diffs and admission errors before apply, objects are labeled like they are on apply
+		if config.PlanDryRun.ValueBool() && r.dynamicClient != nil && r.restMapper != nil {
+			if err := labelResources(resMap, id); err != nil {
+				resp.Diagnostics.AddError("Failed to label generated objects", err.Error())
+				return
+			}
+			if resp.Diagnostics.Append(r.dryRunApply(ctx, resMap, r.getApplyOptions(config))...); resp.Diagnostics.HasError() {
+				return
+			}
 		}
+	}
 
-		objects = append(objects, string(getUnstructuredJson))
+	// Nothing to compare with on create
+	if req.State.Raw.IsNull() {
+		return
+	}
+	var state KustomizeApplyModel
+	if resp.Diagnostics.Append(req.State.Get(ctx, &state)...); resp.Diagnostics.HasError() {
+		return
+	}
+	// The ID is kept once assigned, even if objects are added to or removed from the kustomization later
+	if state.Id.ValueString() != legacyID {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("id"), state.Id)...)
+	}
+	if state.Objects.IsNull() || state.Objects.IsUnknown() {
+		return
 	}
 
-	// Set objects to model
-	objectsModel, diagnostics := types.ListValueFrom(ctx, types.StringType, &objects)
-	data.Objects = objectsModel
-	if resp.Diagnostics.Append(diagnostics...); resp.Diagnostics.HasError() {
+	// Compare generated manifests with refreshed objects, re-apply if any of them was deleted or changed
+	desired, err := decodeManifests(state.Yaml.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to decode generated manifests from state", err.Error())
+		return
+	}
+	var stateObjects []string
+	if resp.Diagnostics.Append(state.Objects.ElementsAs(ctx, &stateObjects, false)...); resp.Diagnostics.HasError() {
 		return
-	}*/
+	}
+	live, err := decodeObjects(stateObjects)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to decode objects from state", err.Error())
+		return
+	}
+	drifted := hasDrift(desired, live, r.getApplyOptions(state).FieldManager)
 
-	if resp.Diagnostics.Append(resp.State.Set(ctx, &data)...); resp.Diagnostics.HasError() {
+	// Objects kept in inventory after a failed prune or apply are pruned by re-applying
+	var prune types.Bool
+	if resp.Diagnostics.Append(resp.Plan.GetAttribute(ctx, path.Root("prune"), &prune)...); resp.Diagnostics.HasError() {
 		return
 	}
+	inventory, diagnostics := getInventory(ctx, state)
+	if resp.Diagnostics.Append(diagnostics...); resp.Diagnostics.HasError() {
+		return
+	}
+	stale := prune.ValueBool() && len(notDesired(inventory, desired)) > 0
+	// Objects applied under the legacy ID, or before objects were labeled, get the label by re-applying
+	unlabeled := state.Id.ValueString() == legacyID || !isLabeled(live, state.Id.ValueString())
+
+	// The field manager may also change through the provider default, objects are then re-applied under the new one
+	fieldManagerChanged := built && (r.getApplyOptions(config).FieldManager != r.getApplyOptions(state).FieldManager ||
+		!isAppliedBy(live, r.getApplyOptions(config).FieldManager))
+
+	switch {
+	case drifted || stale || unlabeled || fieldManagerChanged || (built && (manifests != state.Yaml.ValueString() || !plannedSecrets.Equal(state.Secrets))):
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("objects"), types.ListUnknown(types.StringType))...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("inventory"), types.ListUnknown(inventoryElementType))...)
+	case built:
+		// Generated manifests are unchanged, keep computed attributes stable to avoid plan noise.
+		// Update skips re-applying objects when they are known in plan.
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("objects"), state.Objects)...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("inventory"), state.Inventory)...)
+	}
 }
 
 func (r *KustomizeApplyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
 	// Retrieve resource from plan data
-	var data KustomizeApplyModel
+	var data, state KustomizeApplyModel
 	if resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...); resp.Diagnostics.HasError() {
 		return
 	}
-	data.Id = types.StringValue("test")
+	if resp.Diagnostics.Append(req.State.Get(ctx, &state)...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	updateTimeout, diagnostics := data.Timeouts.Update(ctx, defaultUpdateTimeout)
+	if resp.Diagnostics.Append(diagnostics...); resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	// Generated manifests and live objects are unchanged, only non-kustomize attributes are updated
+	if !data.Objects.IsUnknown() && !data.Inventory.IsUnknown() {
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		return
+	}
 
 	// Run kustomize build and save resmap in yaml
-	resMap, err := kustomizeBuild(r.kustomizer, data)
+	result, err := kustomizeBuild(r.kustomizer, data)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to run kustomize build",
@@ -216,59 +319,71 @@ func (r *KustomizeApplyResource) Update(ctx context.Context, req resource.Update
 		)
 		return
 	}
-	manifests, err := resMap.AsYaml()
-	if err != nil {
-		resp.Diagnostics.AddError("Failed to convert generated manifests to YAML", err.Error())
+	resMap := result.ResMap
+	attributes, diagnostics := newBuildAttributes(ctx, result)
+	if resp.Diagnostics.Append(diagnostics...); resp.Diagnostics.HasError() {
+		return
+	}
+	attributes.set(&data)
+	if data.Id.IsUnknown() {
+		data.Id = types.StringValue(inventoryID(resMap))
+	}
+	if err := labelResources(resMap, data.Id.ValueString()); err != nil {
+		resp.Diagnostics.AddError("Failed to label generated objects", err.Error())
 		return
 	}
-	data.Yaml = types.StringValue(string(manifests))
 
-	// Get server supported resources
-	_, resources, err := r.discoveryClient.ServerGroupsAndResources()
-	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
-		resp.Diagnostics.AddError("Failed to get server groups and resources", err.Error())
+	waitTimeout, err := data.Wait.GetTimeout()
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid wait timeout", err.Error())
 		return
 	}
 
-	var objects []string
-	for _, res := range resMap.Resources() {
-		restClient, err := getRestClientFromResource(r.dynamicClient, resources, res)
-		if err != nil {
-			resp.Diagnostics.AddError("Failed to create rest client from resource", err.Error())
-			return
-		}
+	// Apply objects in phases, CRDs and Namespaces first. Objects which succeeded are saved to state even if others failed.
+	applied, applyDiagnostics := r.applyResMap(ctx, resMap, r.getApplyOptions(data), r.getParallelism(data), waitTimeout)
 
-		// Convert resource to unstructured
-		unstructured, err := kustomizeResourceToUnstructured(res)
-		if err != nil {
-			resp.Diagnostics.AddError("Failed to convert Kustomize resource to unstructured", err.Error())
-			return
-		}
+	priorInventory, diagnostics := getInventory(ctx, state)
+	if resp.Diagnostics.Append(diagnostics...); resp.Diagnostics.HasError() {
+		return
+	}
 
-		createdUnstructured, err := restClient.Apply(ctx, unstructured.GetName(), unstructured, metav1.ApplyOptions{FieldManager: FieldManager})
-		if err != nil {
-			resp.Diagnostics.AddError("Failed to update object", err.Error())
-			return
+	var kept []InventoryModel
+	var pruneErr error
+	switch {
+	case applyDiagnostics.HasError():
+		// Keep objects of prior inventory which weren't applied again, so they are pruned or retried by the next apply
+		kept = notApplied(priorInventory, applied)
+	case data.Prune.ValueBool():
+		// Delete objects which are recorded in prior inventory but no longer generated. If that fails, they are kept in
+		// inventory, so the next apply or destroy deletes them.
+		if pruneErr = r.pruneObjects(ctx, priorInventory, applied, getDeleteOptions(data), data.WaitForDeletion.ValueBool()); pruneErr != nil {
+			kept = notApplied(priorInventory, applied)
 		}
-		appliedJson, err := createdUnstructured.MarshalJSON()
-		if err != nil {
-			resp.Diagnostics.AddError("failed to convert created object to JSON", err.Error())
-			return
-		}
-
-		objects = append(objects, string(appliedJson))
 	}
 
-	// Set objects to model
-	objectsModel, diagnostics := types.ListValueFrom(ctx, types.StringType, &objects)
-	data.Objects = objectsModel
+	data.Objects, data.Inventory, diagnostics = objectAttributes(ctx, applied, kept)
 	if resp.Diagnostics.Append(diagnostics...); resp.Diagnostics.HasError() {
 		return
 	}
 
-	if resp.Diagnostics.Append(resp.State.Set(ctx, &data)...); resp.Diagnostics.HasError() {
+	// Only the remaining objects are applied on retry, as failed updates don't taint the resource
+	if applyDiagnostics.HasError() || pruneErr != nil {
+		resp.Diagnostics.Append(applyDiagnostics...)
+		if pruneErr != nil {
+			resp.Diagnostics.AddError("Failed to prune objects", pruneErr.Error())
+		}
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 		return
 	}
+
+	// Wait for applied objects to become ready, state is saved either way so a failed rollout taints the resource
+	if data.Wait != nil {
+		if err := r.waitForObjects(ctx, applied, waitTimeout); err != nil {
+			resp.Diagnostics.AddError("Failed waiting for objects to become ready", err.Error())
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
 func (r *KustomizeApplyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
@@ -278,33 +393,37 @@ func (r *KustomizeApplyResource) Delete(ctx context.Context, req resource.Delete
 		return
 	}
 
-	// Run kustomize build
-	resMap, err := kustomizeBuild(r.kustomizer, data)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Failed to run kustomize build",
-			err.Error(),
-		)
+	inventory, diagnostics := getInventory(ctx, data)
+	if resp.Diagnostics.Append(diagnostics...); resp.Diagnostics.HasError() {
 		return
 	}
 
-	// Get server supported resources
-	_, resources, err := r.discoveryClient.ServerGroupsAndResources()
-	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
-		resp.Diagnostics.AddError("Failed to get server groups and resources", err.Error())
+	deleteTimeout, diagnostics := data.Timeouts.Delete(ctx, defaultDeleteTimeout)
+	if resp.Diagnostics.Append(diagnostics...); resp.Diagnostics.HasError() {
 		return
 	}
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
 
-	for _, res := range lo.Reverse(resMap.Resources()) {
-		restClient, err := getRestClientFromResource(r.dynamicClient, resources, res)
-		if err != nil {
-			resp.Diagnostics.AddError("Failed to create rest client from resource", err.Error())
-			return
-		}
+	// Delete objects in reverse order of inventory, which is the reverse of the order they were applied in
+	if err := r.deleteObjects(ctx, lo.Reverse(inventory), getDeleteOptions(data), data.WaitForDeletion.ValueBool()); err != nil {
+		resp.Diagnostics.AddError("Failed to delete Kubernetes objects", err.Error())
+		return
+	}
+}
 
-		if err := restClient.Delete(ctx, res.GetName(), metav1.DeleteOptions{}); err != nil {
-			resp.Diagnostics.AddError("Failed to delete Kubernetes object", err.Error())
-			return
-		}
+// getApplyOptions returns server-side apply options, attributes of the resource take precedence over provider defaults.
+func (r *KustomizeApplyResource) getApplyOptions(data KustomizeApplyModel) metav1.ApplyOptions {
+	return r.applyOptions(data.FieldManager, data.ForceConflicts)
+}
+
+// getParallelism returns how many objects of the same phase are applied concurrently.
+func (r *KustomizeApplyResource) getParallelism(data KustomizeApplyModel) int {
+	if !data.Parallelism.IsNull() && !data.Parallelism.IsUnknown() {
+		return int(data.Parallelism.ValueInt64())
+	}
+	if r.parallelism > 0 {
+		return r.parallelism
 	}
+	return defaultParallelism
 }
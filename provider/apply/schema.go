@@ -1,32 +1,165 @@
 package apply
 
 import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/samber/lo"
 )
 
 var kustomizeApplySchema = schema.Schema{
-	Description: "This resource renders Kubernetes manifests using Kustomize and apply the generated manifests, which is equivalent to `kustomize build | kubectl apply -f`.",
+	Description: "This resource renders Kubernetes manifests using Kustomize and apply the generated manifests, which is equivalent to `kustomize build | kubectl apply -f`. If some objects fail to apply on create, the objects which succeeded are saved to state and Terraform marks the resource as tainted. The next apply replaces it, which deletes and re-creates every object, including Namespaces and their contents. Run `terraform untaint` first to only apply the remaining objects.",
 	Attributes: lo.Assign[string, schema.Attribute](
 		kustomizeAttributes,
 		map[string]schema.Attribute{
 			// Required for testing framework
 			"id": schema.StringAttribute{
-				Computed: true,
+				Computed:    true,
+				Description: "SHA-256 hash of the group, kind, namespace and name of every generated object, truncated to 40 characters. It is assigned on create or import and kept afterwards. Every applied object is labeled with `terraform-provider-kustomize/id` set to it, and it can be used as import ID to import the labeled objects.",
 			},
 			// Some read-only attributes
 			"yaml": schema.StringAttribute{
 				Computed:    true,
-				Description: "The generated Kubernetes manifests in yaml format.",
+				Description: "The generated Kubernetes manifests in yaml format. Values of Secret `data` and `stringData` are redacted.",
+			},
+			"secrets": schema.MapAttribute{
+				ElementType: types.StringType,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The generated Secrets in yaml format keyed by API version, kind, namespace and name, e.g. `v1/Secret/ns/name`.",
+			},
+			"source_files": schema.ListAttribute{
+				ElementType: types.StringType,
+				Computed:    true,
+				Description: "The files on disk read by kustomize build, relative to `base_dir`. Files of remote bases are left out.",
+			},
+			"source_hash": schema.StringAttribute{
+				Computed:    true,
+				Description: "SHA-256 hash of the paths and contents of `source_files`. It changes whenever a local file used by the build changes.",
 			},
 			"objects": schema.ListAttribute{
 				ElementType: types.StringType,
 				Computed:    true,
-				Description: "The managed Kubernetes objects in JSON format.",
+				Description: "The managed Kubernetes objects in JSON format. Values of Secret `data` and `stringData` are redacted.",
+			},
+			"inventory": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "The inventory of applied Kubernetes objects, which is used to prune and delete objects without running kustomize build again.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: inventoryAttributes,
+				},
+			},
+			"delete_propagation": schema.StringAttribute{
+				Optional:    true,
+				Description: "The propagation policy used when deleting objects, one of `foreground`, `background` or `orphan`. Defaults to the policy of each object kind, which is `background` for most of them.",
+				Validators: []validator.String{
+					stringvalidator.OneOf("foreground", "background", "orphan"),
+				},
+			},
+			"field_manager": schema.StringAttribute{
+				Optional:    true,
+				Description: "The field manager name used for server-side apply. Defaults to the provider `field_manager`, which defaults to `terraform-provider-kustomize`.",
+			},
+			"force_conflicts": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Force server-side apply to take ownership of fields managed by other field managers. Defaults to the provider `force_conflicts`.",
+			},
+			"parallelism": schema.Int64Attribute{
+				Optional:    true,
+				Description: "The maximum number of objects applied concurrently within the same phase. Defaults to the provider `parallelism`, which defaults to `10`.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"base_dir": schema.StringAttribute{
+				Optional:    true,
+				Description: "Directory in which the generated kustomization.yaml is placed. Relative paths in kustomization attributes, `path` and `files` are resolved against it. Defaults to the working directory, set it to `path.module` to resolve paths relative to a child module. A relative base_dir is relative to the working directory.",
+			},
+			"files": schema.MapAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "files is a map of file paths to contents, which are kept in memory relative to `base_dir`. They can be referenced by resources, patches, generators, transformers and replacements, and hide files on disk at the same path.",
+			},
+			"inline_resources": schema.ListAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "inline_resources is a list of manifests in yaml format, which are added to resources. Kustomization attributes such as namespace, labels, images and patches apply to them too.",
+			},
+			"path": schema.StringAttribute{
+				Optional:    true,
+				Description: "Path of a kustomization directory to build, relative to `base_dir`. Other kustomization attributes are applied on top of it as an overlay.",
+			},
+			"plan_dry_run": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Send every generated object to the Kubernetes API server as a server-side dry-run apply during plan. Objects which would be created or changed are reported with a field-level diff, and admission or schema errors fail the plan instead of the apply.",
+			},
+			"prune": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(true),
+				Description: "Delete objects which are no longer generated by the kustomization on update, which is equivalent to `kubectl apply --prune`. Defaults to `true`.",
+			},
+			"wait_for_deletion": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Wait until deleted and pruned objects are gone from the cluster, including their finalizers, e.g. for Namespaces.",
+			},
+			"wait": schema.SingleNestedAttribute{
+				Optional:    true,
+				Description: "Wait for applied objects to become ready. Deployments, StatefulSets and DaemonSets must finish their rollouts, Jobs must complete, CRDs must be established, PersistentVolumeClaims must be bound and LoadBalancer Services must get an ingress address. Any other object must have a `Ready` condition with status `True`, if it has one.",
+				Attributes: map[string]schema.Attribute{
+					"timeout": schema.StringAttribute{
+						Optional:    true,
+						Description: "How long to wait for all objects to become ready, e.g. `30s` or `10m`. It also limits waiting for CustomResourceDefinitions to become established before their custom resources are applied. Defaults to `5m`.",
+						Validators: []validator.String{
+							durationValidator{},
+						},
+					},
+				},
 			},
 		},
 	),
+	Blocks: map[string]schema.Block{
+		"timeouts": timeouts.Block(context.Background(), timeouts.Opts{
+			Create: true,
+			Update: true,
+			Delete: true,
+		}),
+	},
+}
+
+var inventoryAttributes = map[string]schema.Attribute{
+	"group": schema.StringAttribute{
+		Computed:    true,
+		Description: "The API group of the object, empty for the core group",
+	},
+	"version": schema.StringAttribute{
+		Computed:    true,
+		Description: "The API version of the object",
+	},
+	"kind": schema.StringAttribute{
+		Computed:    true,
+		Description: "The kind of the object",
+	},
+	"namespace": schema.StringAttribute{
+		Computed:    true,
+		Description: "The namespace of the object, empty for cluster scoped objects",
+	},
+	"name": schema.StringAttribute{
+		Computed:    true,
+		Description: "The name of the object",
+	},
+	"uid": schema.StringAttribute{
+		Computed:    true,
+		Description: "The UID assigned to the object by the API server",
+	},
 }
 
 var kustomizeAttributes = map[string]schema.Attribute{
@@ -99,7 +232,7 @@ var kustomizeAttributes = map[string]schema.Attribute{
 	},
 	"helm_charts": schema.ListNestedAttribute{
 		Optional:    true,
-		Description: "helm_charts is a list of helm chart configuration instances",
+		Description: "helm_charts is a list of helm chart configuration instances. Helm chart inflation isn't enabled in the kustomize build of this provider, so builds with helm_charts fail. Helm runs outside of the in-memory file system and would pull charts to `chart_home` below the kustomization root, i.e. into `base_dir` on disk.",
 		NestedObject: schema.NestedAttributeObject{
 			Attributes: map[string]schema.Attribute{
 				"name": schema.StringAttribute{
@@ -492,3 +625,31 @@ var replacementsInlineTargetObjectAttributes = map[string]schema.Attribute{
 		Description: "The namespace of the referent",
 	},
 }
+
+// durationValidator validates that a string is a non-negative duration accepted by time.ParseDuration.
+type durationValidator struct{}
+
+func (v durationValidator) Description(_ context.Context) string {
+	return "value must be a duration, e.g. `30s` or `10m`"
+}
+
+func (v durationValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v durationValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() || req.ConfigValue.ValueString() == "" {
+		return
+	}
+	duration, err := time.ParseDuration(req.ConfigValue.ValueString())
+	if err == nil && duration < 0 {
+		err = fmt.Errorf("duration must not be negative")
+	}
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Duration",
+			fmt.Sprintf("Attribute %s %s, got %q: %s", req.Path, v.Description(ctx), req.ConfigValue.ValueString(), err.Error()),
+		)
+	}
+}
@@ -0,0 +1,28 @@
+package apply
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+const (
+	defaultCreateTimeout = 20 * time.Minute
+	defaultUpdateTimeout = 20 * time.Minute
+	defaultDeleteTimeout = 10 * time.Minute
+)
+
+func pendingResourcesError(operation string, pending []*resource.Resource) string {
+	return fmt.Sprintf("Timed out %s objects, still pending: %s", operation, strings.Join(lo.Map(pending, func(res *resource.Resource, _ int) string {
+		return resourceName(res)
+	}), ", "))
+}
+
+func pendingInventoryError(operation string, pending []InventoryModel) string {
+	return fmt.Sprintf("Timed out %s objects, still pending: %s", operation, strings.Join(lo.Map(pending, func(item InventoryModel, _ int) string {
+		return objectName(item.Unstructured())
+	}), ", "))
+}
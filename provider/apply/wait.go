@@ -0,0 +1,240 @@
+package apply
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	defaultWaitTimeout = 5 * time.Minute
+	waitPollInterval   = 2 * time.Second
+)
+
+// waitForObjects polls every object until it is ready, fails or timeout is reached.
+// On timeout the returned error lists the objects which are still pending.
+func (r *KustomizeApplyResource) waitForObjects(ctx context.Context, objects []*unstructured.Unstructured, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	pending := objects
+	messages := map[*unstructured.Unstructured]string{}
+	for {
+		var stillPending []*unstructured.Unstructured
+		for _, object := range pending {
+			restClient, err := r.getRestClientFromUnstructured(object)
+			if err != nil {
+				return fmt.Errorf("failed to create rest client from object %s: %w", objectName(object), err)
+			}
+			live, err := restClient.Get(ctx, object.GetName(), metav1.GetOptions{})
+			if err != nil {
+				if ctx.Err() != nil {
+					stillPending = append(stillPending, object)
+					continue
+				}
+				return fmt.Errorf("failed to get object %s: %w", objectName(object), err)
+			}
+
+			ready, message, err := isObjectReady(live)
+			if err != nil {
+				return fmt.Errorf("%s failed: %w", objectName(object), err)
+			}
+			if !ready {
+				messages[object] = message
+				stillPending = append(stillPending, object)
+			}
+		}
+
+		pending = stillPending
+		if len(pending) == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for objects to become ready: %s", strings.Join(lo.Map(pending, func(object *unstructured.Unstructured, _ int) string {
+				if messages[object] == "" {
+					return objectName(object)
+				}
+				return fmt.Sprintf("%s (%s)", objectName(object), messages[object])
+			}), ", "))
+		case <-time.After(waitPollInterval):
+		}
+	}
+}
+
+// isObjectReady reports whether object is healthy. A non-nil error means the object reached a terminal failure
+// and waiting any longer is pointless. Otherwise message explains why a pending object isn't ready yet.
+func isObjectReady(object *unstructured.Unstructured) (bool, string, error) {
+	gk := object.GroupVersionKind().GroupKind()
+	switch {
+	case gk.Group == "apps" && gk.Kind == "Deployment":
+		return isDeploymentReady(object)
+	case gk.Group == "apps" && gk.Kind == "StatefulSet":
+		return isStatefulSetReady(object)
+	case gk.Group == "apps" && gk.Kind == "DaemonSet":
+		return isDaemonSetReady(object)
+	case gk.Group == "batch" && gk.Kind == "Job":
+		return isJobReady(object)
+	case gk.Group == "apiextensions.k8s.io" && gk.Kind == "CustomResourceDefinition":
+		return isCRDReady(object)
+	case gk.Group == "" && gk.Kind == "PersistentVolumeClaim":
+		return isPVCReady(object)
+	case gk.Group == "" && gk.Kind == "Service":
+		return isServiceReady(object)
+	default:
+		return isConditionReady(object)
+	}
+}
+
+func isDeploymentReady(object *unstructured.Unstructured) (bool, string, error) {
+	if !isGenerationObserved(object) {
+		return false, "waiting for rollout to be observed", nil
+	}
+	if condition, found := getCondition(object, "Progressing"); found && condition["reason"] == "ProgressDeadlineExceeded" {
+		return false, "", fmt.Errorf("rollout exceeded its progress deadline")
+	}
+	replicas := getReplicas(object)
+	updated, _, _ := unstructured.NestedInt64(object.Object, "status", "updatedReplicas")
+	available, _, _ := unstructured.NestedInt64(object.Object, "status", "availableReplicas")
+	total, _, _ := unstructured.NestedInt64(object.Object, "status", "replicas")
+	switch {
+	case updated < replicas:
+		return false, fmt.Sprintf("%d of %d replicas updated", updated, replicas), nil
+	case total > updated:
+		return false, fmt.Sprintf("%d old replicas pending termination", total-updated), nil
+	case available < replicas:
+		return false, fmt.Sprintf("%d of %d replicas available", available, replicas), nil
+	}
+	return true, "", nil
+}
+
+func isStatefulSetReady(object *unstructured.Unstructured) (bool, string, error) {
+	if !isGenerationObserved(object) {
+		return false, "waiting for rollout to be observed", nil
+	}
+	replicas := getReplicas(object)
+	ready, _, _ := unstructured.NestedInt64(object.Object, "status", "readyReplicas")
+	if ready < replicas {
+		return false, fmt.Sprintf("%d of %d replicas ready", ready, replicas), nil
+	}
+
+	// Only rolling updates are driven by the controller, OnDelete strategy requires manual pod deletion
+	strategy, _, _ := unstructured.NestedString(object.Object, "spec", "updateStrategy", "type")
+	if strategy != "" && strategy != "RollingUpdate" {
+		return true, "", nil
+	}
+	partition, _, _ := unstructured.NestedInt64(object.Object, "spec", "updateStrategy", "rollingUpdate", "partition")
+	updated, _, _ := unstructured.NestedInt64(object.Object, "status", "updatedReplicas")
+	if partition > 0 {
+		if updated < replicas-partition {
+			return false, fmt.Sprintf("%d of %d replicas updated", updated, replicas-partition), nil
+		}
+		return true, "", nil
+	}
+	currentRevision, _, _ := unstructured.NestedString(object.Object, "status", "currentRevision")
+	updateRevision, _, _ := unstructured.NestedString(object.Object, "status", "updateRevision")
+	if currentRevision != updateRevision {
+		return false, fmt.Sprintf("%d of %d replicas updated", updated, replicas), nil
+	}
+	return true, "", nil
+}
+
+func isDaemonSetReady(object *unstructured.Unstructured) (bool, string, error) {
+	if !isGenerationObserved(object) {
+		return false, "waiting for rollout to be observed", nil
+	}
+	desired, _, _ := unstructured.NestedInt64(object.Object, "status", "desiredNumberScheduled")
+	updated, _, _ := unstructured.NestedInt64(object.Object, "status", "updatedNumberScheduled")
+	available, _, _ := unstructured.NestedInt64(object.Object, "status", "numberAvailable")
+	switch {
+	case updated < desired:
+		return false, fmt.Sprintf("%d of %d pods updated", updated, desired), nil
+	case available < desired:
+		return false, fmt.Sprintf("%d of %d pods available", available, desired), nil
+	}
+	return true, "", nil
+}
+
+func isJobReady(object *unstructured.Unstructured) (bool, string, error) {
+	if condition, found := getCondition(object, "Failed"); found && condition["status"] == "True" {
+		return false, "", errors.New(conditionMessage(condition, "job failed"))
+	}
+	if condition, found := getCondition(object, "Complete"); found && condition["status"] == "True" {
+		return true, "", nil
+	}
+	return false, "waiting for job to complete", nil
+}
+
+func isCRDReady(object *unstructured.Unstructured) (bool, string, error) {
+	if condition, found := getCondition(object, "Established"); found && condition["status"] == "True" {
+		return true, "", nil
+	}
+	return false, "waiting for CRD to be established", nil
+}
+
+func isPVCReady(object *unstructured.Unstructured) (bool, string, error) {
+	phase, _, _ := unstructured.NestedString(object.Object, "status", "phase")
+	if phase != "Bound" {
+		return false, fmt.Sprintf("phase is %q", phase), nil
+	}
+	return true, "", nil
+}
+
+func isServiceReady(object *unstructured.Unstructured) (bool, string, error) {
+	serviceType, _, _ := unstructured.NestedString(object.Object, "spec", "type")
+	if serviceType != "LoadBalancer" {
+		return true, "", nil
+	}
+	ingress, _, _ := unstructured.NestedSlice(object.Object, "status", "loadBalancer", "ingress")
+	if len(ingress) == 0 {
+		return false, "waiting for load balancer ingress", nil
+	}
+	return true, "", nil
+}
+
+// isConditionReady falls back to the standard Ready condition, objects without it are considered ready.
+func isConditionReady(object *unstructured.Unstructured) (bool, string, error) {
+	condition, found := getCondition(object, "Ready")
+	if !found || condition["status"] == "True" {
+		return true, "", nil
+	}
+	return false, conditionMessage(condition, "waiting for Ready condition"), nil
+}
+
+func isGenerationObserved(object *unstructured.Unstructured) bool {
+	observedGeneration, found, _ := unstructured.NestedInt64(object.Object, "status", "observedGeneration")
+	return found && observedGeneration >= object.GetGeneration()
+}
+
+func getReplicas(object *unstructured.Unstructured) int64 {
+	replicas, found, _ := unstructured.NestedInt64(object.Object, "spec", "replicas")
+	if !found {
+		return 1
+	}
+	return replicas
+}
+
+func getCondition(object *unstructured.Unstructured, conditionType string) (map[string]interface{}, bool) {
+	conditions, _, _ := unstructured.NestedSlice(object.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if ok && condition["type"] == conditionType {
+			return condition, true
+		}
+	}
+	return nil, false
+}
+
+func conditionMessage(condition map[string]interface{}, fallback string) string {
+	if message, ok := condition["message"].(string); ok && message != "" {
+		return message
+	}
+	return fallback
+}
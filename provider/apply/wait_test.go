@@ -0,0 +1,187 @@
+package apply
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// decodeLiveObject decodes manifest the way the dynamic client decodes objects, with integers as int64.
+func decodeLiveObject(t *testing.T, manifest string) *unstructured.Unstructured {
+	t.Helper()
+	content, err := utilyaml.ToJSON([]byte(manifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	object := &unstructured.Unstructured{}
+	if err := object.UnmarshalJSON(content); err != nil {
+		t.Fatal(err)
+	}
+	return object
+}
+
+func TestIsObjectReady(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		want     bool
+		wantErr  bool
+	}{
+		{
+			name: "deployment rolled out",
+			manifest: `apiVersion: apps/v1
+kind: Deployment
+metadata: {name: app, generation: 2}
+spec: {replicas: 2}
+status: {observedGeneration: 2, replicas: 2, updatedReplicas: 2, availableReplicas: 2}`,
+			want: true,
+		},
+		{
+			name: "deployment generation not observed",
+			manifest: `apiVersion: apps/v1
+kind: Deployment
+metadata: {name: app, generation: 3}
+spec: {replicas: 2}
+status: {observedGeneration: 2, replicas: 2, updatedReplicas: 2, availableReplicas: 2}`,
+			want: false,
+		},
+		{
+			name: "deployment old replicas pending termination",
+			manifest: `apiVersion: apps/v1
+kind: Deployment
+metadata: {name: app, generation: 2}
+spec: {replicas: 2}
+status: {observedGeneration: 2, replicas: 3, updatedReplicas: 2, availableReplicas: 2}`,
+			want: false,
+		},
+		{
+			name: "deployment progress deadline exceeded",
+			manifest: `apiVersion: apps/v1
+kind: Deployment
+metadata: {name: app, generation: 2}
+spec: {replicas: 2}
+status:
+  observedGeneration: 2
+  conditions: [{type: Progressing, status: "False", reason: ProgressDeadlineExceeded}]`,
+			wantErr: true,
+		},
+		{
+			name: "statefulset revision not updated",
+			manifest: `apiVersion: apps/v1
+kind: StatefulSet
+metadata: {name: db, generation: 1}
+spec: {replicas: 1}
+status: {observedGeneration: 1, readyReplicas: 1, currentRevision: db-1, updateRevision: db-2}`,
+			want: false,
+		},
+		{
+			name: "statefulset partitioned rollout",
+			manifest: `apiVersion: apps/v1
+kind: StatefulSet
+metadata: {name: db, generation: 1}
+spec:
+  replicas: 3
+  updateStrategy: {type: RollingUpdate, rollingUpdate: {partition: 2}}
+status: {observedGeneration: 1, readyReplicas: 3, updatedReplicas: 1, currentRevision: db-1, updateRevision: db-2}`,
+			want: true,
+		},
+		{
+			name: "statefulset on delete strategy",
+			manifest: `apiVersion: apps/v1
+kind: StatefulSet
+metadata: {name: db, generation: 1}
+spec:
+  replicas: 1
+  updateStrategy: {type: OnDelete}
+status: {observedGeneration: 1, readyReplicas: 1, currentRevision: db-1, updateRevision: db-2}`,
+			want: true,
+		},
+		{
+			name: "daemonset pods unavailable",
+			manifest: `apiVersion: apps/v1
+kind: DaemonSet
+metadata: {name: agent, generation: 1}
+status: {observedGeneration: 1, desiredNumberScheduled: 3, updatedNumberScheduled: 3, numberAvailable: 2}`,
+			want: false,
+		},
+		{
+			name: "job complete",
+			manifest: `apiVersion: batch/v1
+kind: Job
+metadata: {name: migrate}
+status:
+  conditions: [{type: Complete, status: "True"}]`,
+			want: true,
+		},
+		{
+			name: "job failed",
+			manifest: `apiVersion: batch/v1
+kind: Job
+metadata: {name: migrate}
+status:
+  conditions: [{type: Failed, status: "True", message: BackoffLimitExceeded}]`,
+			wantErr: true,
+		},
+		{
+			name: "crd not established",
+			manifest: `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata: {name: widgets.example.com}
+status:
+  conditions: [{type: Established, status: "False"}]`,
+			want: false,
+		},
+		{
+			name: "pvc pending",
+			manifest: `apiVersion: v1
+kind: PersistentVolumeClaim
+metadata: {name: data}
+status: {phase: Pending}`,
+			want: false,
+		},
+		{
+			name: "cluster ip service",
+			manifest: `apiVersion: v1
+kind: Service
+metadata: {name: app}
+spec: {type: ClusterIP}`,
+			want: true,
+		},
+		{
+			name: "load balancer service without ingress",
+			manifest: `apiVersion: v1
+kind: Service
+metadata: {name: app}
+spec: {type: LoadBalancer}`,
+			want: false,
+		},
+		{
+			name: "ready condition false",
+			manifest: `apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata: {name: tls}
+status:
+  conditions: [{type: Ready, status: "False"}]`,
+			want: false,
+		},
+		{
+			name: "object without conditions",
+			manifest: `apiVersion: v1
+kind: ConfigMap
+metadata: {name: config}`,
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := isObjectReady(decodeLiveObject(t, tt.manifest))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("isObjectReady() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("isObjectReady() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
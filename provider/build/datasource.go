@@ -2,15 +2,16 @@ package build
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/samber/lo"
 	"sigs.k8s.io/kustomize/api/krusty"
-	"sigs.k8s.io/kustomize/kyaml/yaml"
 
 	"github.com/webzyno/terraform-provider-kustomize/client"
-	"github.com/webzyno/terraform-provider-kustomize/virtfs"
+	"github.com/webzyno/terraform-provider-kustomize/kustomize"
 )
 
 type KustomizeBuildDataSource struct {
@@ -51,42 +52,62 @@ func (d *KustomizeBuildDataSource) Read(ctx context.Context, req datasource.Read
 	// Retrieve data source config
 	var data KustomizeBuildModel
 	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
-	data.Id = types.StringValue("test")
 
-	// Create overlay filesystem and write kustomization.yaml
-	fs, err := virtfs.NewOverlayFS()
-	if err != nil {
-		resp.Diagnostics.AddError("Failed to create OverlayFS", err.Error())
-		return
-	}
-	kustomization := ToKustomization(data)
-	kustomizationContent, err := yaml.Marshal(&kustomization)
+	// Build the generated kustomization.yaml, on top of the kustomization directory at path if set
+	result, err := kustomize.Build(d.kustomizer, ToKustomization(data), kustomize.BuildOptions{
+		BaseDir:         data.BaseDir.ValueString(),
+		Path:            data.Path.ValueString(),
+		InlineResources: lo.Map(data.InlineResources, func(manifest types.String, _ int) string { return manifest.ValueString() }),
+		Files:           data.Files,
+	})
 	if err != nil {
 		resp.Diagnostics.AddError(
-			"Unexpected error during marshaling kustomization.yaml",
-			err.Error(),
-		)
-	}
-	if err := fs.WriteFile(virtfs.KUSTOMIZATION, kustomizationContent); err != nil {
-		resp.Diagnostics.AddError(
-			"Failed to write kustomization.yaml to file system",
+			"Unexpected error during kustomize build",
 			err.Error(),
 		)
+		return
 	}
 
-	resMap, err := d.kustomizer.Run(fs, ".")
+	// Secret values are only kept in the sensitive secrets attribute
+	redacted, secrets, err := kustomize.RedactSecrets(result.ResMap)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Unexpected error during kustomize build",
-			err.Error(),
-		)
+		resp.Diagnostics.AddError("Failed to redact Secrets", err.Error())
+		return
 	}
-
-	manifests, err := resMap.AsYaml()
+	manifests, err := redacted.AsYaml()
 	if err != nil {
 		resp.Diagnostics.AddError("Failed to convert generated manifests to YAML", err.Error())
+		return
 	}
 	data.Yaml = types.StringValue(string(manifests))
+	secretsModel, diagnostics := types.MapValueFrom(ctx, types.StringType, secrets)
+	data.Secrets = secretsModel
+	resp.Diagnostics.Append(diagnostics...)
+
+	// Split manifests per object, keyed by a stable ID
+	ids := []string{}
+	objectManifests := map[string]string{}
+	for _, res := range redacted.Resources() {
+		manifest, err := res.AsYAML()
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to convert generated manifests to YAML", err.Error())
+			return
+		}
+		id := kustomize.ResourceID(res)
+		ids = append(ids, id)
+		objectManifests[id] = string(manifest)
+	}
+	idsModel, diagnostics := types.ListValueFrom(ctx, types.StringType, ids)
+	data.Ids = idsModel
+	resp.Diagnostics.Append(diagnostics...)
+	manifestsModel, diagnostics := types.MapValueFrom(ctx, types.StringType, objectManifests)
+	data.Manifests = manifestsModel
+	resp.Diagnostics.Append(diagnostics...)
+	sourceFilesModel, diagnostics := types.ListValueFrom(ctx, types.StringType, result.SourceFiles())
+	data.SourceFiles = sourceFilesModel
+	resp.Diagnostics.Append(diagnostics...)
+	data.SourceHash = types.StringValue(result.SourceHash())
+	data.Id = types.StringValue(fmt.Sprintf("%x", sha256.Sum256(append(result.Kustomization, manifests...))))
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -11,8 +11,17 @@ import (
 
 type KustomizeBuildModel struct {
 	// Custom fileds
-	Id   types.String `tfsdk:"id"`
-	Yaml types.String `tfsdk:"yaml"`
+	Id              types.String      `tfsdk:"id"`
+	Yaml            types.String      `tfsdk:"yaml"`
+	Path            types.String      `tfsdk:"path"`
+	BaseDir         types.String      `tfsdk:"base_dir"`
+	InlineResources []types.String    `tfsdk:"inline_resources"`
+	Files           map[string]string `tfsdk:"files"`
+	Manifests       types.Map         `tfsdk:"manifests"`
+	Ids             types.List        `tfsdk:"ids"`
+	Secrets         types.Map         `tfsdk:"secrets"`
+	SourceFiles     types.List        `tfsdk:"source_files"`
+	SourceHash      types.String      `tfsdk:"source_hash"`
 
 	CommonAnnotations  map[string]string           `tfsdk:"common_annotations"`
 	BuildMetadata      []types.String              `tfsdk:"build_metadata"`
@@ -13,12 +13,56 @@ var kustomizeBuildSchema = schema.Schema{
 		map[string]schema.Attribute{
 			// Required for testing framework
 			"id": schema.StringAttribute{
-				Computed: true,
+				Computed:    true,
+				Description: "SHA-256 hash of the generated kustomization.yaml and the generated manifests.",
 			},
 			// Some read-only attributes
+			"base_dir": schema.StringAttribute{
+				Optional:    true,
+				Description: "Directory in which the generated kustomization.yaml is placed. Relative paths in kustomization attributes, `path` and `files` are resolved against it. Defaults to the working directory, set it to `path.module` to resolve paths relative to a child module. A relative base_dir is relative to the working directory.",
+			},
+			"files": schema.MapAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "files is a map of file paths to contents, which are kept in memory relative to `base_dir`. They can be referenced by resources, patches, generators, transformers and replacements, and hide files on disk at the same path.",
+			},
+			"inline_resources": schema.ListAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "inline_resources is a list of manifests in yaml format, which are added to resources. Kustomization attributes such as namespace, labels, images and patches apply to them too.",
+			},
+			"path": schema.StringAttribute{
+				Optional:    true,
+				Description: "Path of a kustomization directory to build, relative to `base_dir`. Other kustomization attributes are applied on top of it as an overlay.",
+			},
 			"yaml": schema.StringAttribute{
 				Computed:    true,
-				Description: "The generated Kubernetes manifests in yaml format.",
+				Description: "The generated Kubernetes manifests in yaml format. Values of Secret `data` and `stringData` are redacted.",
+			},
+			"manifests": schema.MapAttribute{
+				ElementType: types.StringType,
+				Computed:    true,
+				Description: "The generated Kubernetes manifests in yaml format keyed by API version, kind, namespace and name, e.g. `apps/v1/Deployment/ns/name`. The namespace is left out for objects without one. Values of Secret `data` and `stringData` are redacted.",
+			},
+			"ids": schema.ListAttribute{
+				ElementType: types.StringType,
+				Computed:    true,
+				Description: "The keys of `manifests` in build order.",
+			},
+			"secrets": schema.MapAttribute{
+				ElementType: types.StringType,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The generated Secrets in yaml format keyed like `manifests`, e.g. `v1/Secret/ns/name`. Secret values are redacted from `manifests`, use this attribute to apply Secrets.",
+			},
+			"source_files": schema.ListAttribute{
+				ElementType: types.StringType,
+				Computed:    true,
+				Description: "The files on disk read by kustomize build, relative to `base_dir`. Files of remote bases are left out.",
+			},
+			"source_hash": schema.StringAttribute{
+				Computed:    true,
+				Description: "SHA-256 hash of the paths and contents of `source_files`. It changes whenever a local file used by the build changes.",
 			},
 		},
 	),
@@ -94,7 +138,7 @@ var kustomizeAttributes = map[string]schema.Attribute{
 	},
 	"helm_charts": schema.ListNestedAttribute{
 		Optional:    true,
-		Description: "helm_charts is a list of helm chart configuration instances",
+		Description: "helm_charts is a list of helm chart configuration instances. Helm chart inflation isn't enabled in the kustomize build of this provider, so builds with helm_charts fail. Helm runs outside of the in-memory file system and would pull charts to `chart_home` below the kustomization root, i.e. into `base_dir` on disk.",
 		NestedObject: schema.NestedAttributeObject{
 			Attributes: map[string]schema.Attribute{
 				"name": schema.StringAttribute{
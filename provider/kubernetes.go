@@ -31,8 +31,26 @@ func newDiscoveryClient(model KubernetesModel) (discovery.DiscoveryInterface, er
 	return discovery.NewDiscoveryClientForConfig(config)
 }
 
-// Reference: https://github.com/hashicorp/terraform-provider-kubernetes/blob/main/kubernetes/provider.go#L494
+// newDefaultNamespace returns the namespace of the selected kubeconfig context, or "default" if it doesn't set one.
+func newDefaultNamespace(model KubernetesModel) (string, error) {
+	cc, err := createClientConfig(model)
+	if err != nil {
+		return "", err
+	}
+	namespace, _, err := cc.Namespace()
+	return namespace, err
+}
+
 func createKubernetesConfig(model KubernetesModel) (*rest.Config, error) {
+	cc, err := createClientConfig(model)
+	if err != nil {
+		return nil, err
+	}
+	return cc.ClientConfig()
+}
+
+// Reference: https://github.com/hashicorp/terraform-provider-kubernetes/blob/main/kubernetes/provider.go#L494
+func createClientConfig(model KubernetesModel) (clientcmd.ClientConfig, error) {
 	overrides := &clientcmd.ConfigOverrides{}
 	loader := &clientcmd.ClientConfigLoadingRules{}
 
@@ -109,6 +127,5 @@ func createKubernetesConfig(model KubernetesModel) (*rest.Config, error) {
 	}
 
 	// Create configuration
-	cc := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loader, overrides)
-	return cc.ClientConfig()
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loader, overrides), nil
 }
@@ -1,6 +1,8 @@
 package provider
 
 import (
+	"fmt"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
@@ -29,3 +31,204 @@ func TestAccKustomizeBuild_basic(t *testing.T) {
 		},
 	})
 }
+
+func TestAccKustomizeBuild_manifests(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: TestAccProtoV6Providers,
+		Steps: []resource.TestStep{
+			{
+				Config: `data "kustomize_build" "test" {
+						namespace = "test"
+						inline_resources = [
+							jsonencode({
+								apiVersion = "v1"
+								kind       = "ConfigMap"
+								metadata   = { name = "config" }
+								data       = { key = "value" }
+							}),
+							jsonencode({
+								apiVersion = "v1"
+								kind       = "Secret"
+								metadata   = { name = "credentials" }
+								stringData = { password = "secret" }
+							}),
+						]
+					}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.kustomize_build.test", "ids.#", "2"),
+					resource.TestCheckResourceAttr("data.kustomize_build.test", "ids.0", "v1/ConfigMap/test/config"),
+					resource.TestCheckResourceAttr("data.kustomize_build.test", "ids.1", "v1/Secret/test/credentials"),
+					resource.TestCheckResourceAttr("data.kustomize_build.test", "manifests.%", "2"),
+					resource.TestMatchResourceAttr("data.kustomize_build.test", "manifests.v1/ConfigMap/test/config", regexp.MustCompile(`key: value`)),
+					resource.TestMatchResourceAttr("data.kustomize_build.test", "manifests.v1/Secret/test/credentials", regexp.MustCompile(`\(sensitive value\)`)),
+					resource.TestMatchResourceAttr("data.kustomize_build.test", "secrets.v1/Secret/test/credentials", regexp.MustCompile(`password: secret`)),
+				),
+			},
+		},
+	})
+}
+
+func TestAccKustomizeBuild_path(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: TestAccProtoV6Providers,
+		Steps: []resource.TestStep{
+			{
+				Config: `data "kustomize_build" "test" {
+						base_dir = "../test"
+						path     = "app"
+					}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.kustomize_build.test", "ids.#", "1"),
+					resource.TestCheckResourceAttr("data.kustomize_build.test", "ids.0", "v1/ConfigMap/app"),
+				),
+			},
+			{
+				Config: `data "kustomize_build" "test" {
+						base_dir  = "../test"
+						path      = "app"
+						namespace = "overlay"
+					}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.kustomize_build.test", "ids.#", "1"),
+					resource.TestCheckResourceAttr("data.kustomize_build.test", "ids.0", "v1/ConfigMap/overlay/app"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccKustomizeBuild_inlineResources(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: TestAccProtoV6Providers,
+		Steps: []resource.TestStep{
+			{
+				Config: `data "kustomize_build" "test" {
+						name_prefix   = "prefix-"
+						common_labels = { app = "inline" }
+						inline_resources = [
+							jsonencode({
+								apiVersion = "v1"
+								kind       = "ConfigMap"
+								metadata   = { name = "config" }
+							}),
+						]
+					}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.kustomize_build.test", "ids.#", "1"),
+					resource.TestCheckResourceAttr("data.kustomize_build.test", "ids.0", "v1/ConfigMap/prefix-config"),
+					resource.TestMatchResourceAttr("data.kustomize_build.test", "manifests.v1/ConfigMap/prefix-config", regexp.MustCompile(`app: inline`)),
+					resource.TestCheckResourceAttr("data.kustomize_build.test", "source_files.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccKustomizeBuild_files(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: TestAccProtoV6Providers,
+		Steps: []resource.TestStep{
+			{
+				Config: `data "kustomize_build" "test" {
+						resources = ["generated/configmap.yaml"]
+						files = {
+							"generated/configmap.yaml" = jsonencode({
+								apiVersion = "v1"
+								kind       = "ConfigMap"
+								metadata   = { name = "generated" }
+							})
+						}
+					}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.kustomize_build.test", "ids.#", "1"),
+					resource.TestCheckResourceAttr("data.kustomize_build.test", "ids.0", "v1/ConfigMap/generated"),
+				),
+			},
+			{
+				Config: `data "kustomize_build" "test" {
+						base_dir = "../test"
+						path     = "app"
+						files = {
+							"app/configmap.yaml" = jsonencode({
+								apiVersion = "v1"
+								kind       = "ConfigMap"
+								metadata   = { name = "app" }
+								data       = { source = "memory" }
+							})
+						}
+					}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.kustomize_build.test", "ids.0", "v1/ConfigMap/app"),
+					resource.TestMatchResourceAttr("data.kustomize_build.test", "manifests.v1/ConfigMap/app", regexp.MustCompile(`source: memory`)),
+				),
+			},
+		},
+	})
+}
+
+func TestAccKustomizeBuild_baseDir(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: TestAccProtoV6Providers,
+		Steps: []resource.TestStep{
+			{
+				Config: `data "kustomize_build" "test" {
+						base_dir  = "../test/app"
+						resources = ["configmap.yaml"]
+						namespace = "base-dir"
+					}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.kustomize_build.test", "ids.#", "1"),
+					resource.TestCheckResourceAttr("data.kustomize_build.test", "ids.0", "v1/ConfigMap/base-dir/app"),
+					resource.TestCheckResourceAttr("data.kustomize_build.test", "source_files.0", "configmap.yaml"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccKustomizeBuild_sourceHash(t *testing.T) {
+	var diskHash string
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: TestAccProtoV6Providers,
+		Steps: []resource.TestStep{
+			{
+				Config: `data "kustomize_build" "test" {
+						base_dir = "../test"
+						path     = "app"
+					}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.kustomize_build.test", "source_files.#", "2"),
+					resource.TestCheckResourceAttr("data.kustomize_build.test", "source_files.0", "app/configmap.yaml"),
+					resource.TestCheckResourceAttr("data.kustomize_build.test", "source_files.1", "app/kustomization.yaml"),
+					resource.TestCheckResourceAttrWith("data.kustomize_build.test", "source_hash", func(value string) error {
+						diskHash = value
+						return nil
+					}),
+				),
+			},
+			{
+				Config: `data "kustomize_build" "test" {
+						base_dir = "../test"
+						path     = "app"
+						files = {
+							"app/configmap.yaml" = jsonencode({
+								apiVersion = "v1"
+								kind       = "ConfigMap"
+								metadata   = { name = "app" }
+							})
+						}
+					}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.kustomize_build.test", "source_files.#", "1"),
+					resource.TestCheckResourceAttr("data.kustomize_build.test", "source_files.0", "app/kustomization.yaml"),
+					resource.TestCheckResourceAttrWith("data.kustomize_build.test", "source_hash", func(value string) error {
+						if value == diskHash {
+							return fmt.Errorf("source_hash %s didn't change when a source file was replaced", value)
+						}
+						return nil
+					}),
+				),
+			},
+		},
+	})
+}
@@ -3,7 +3,11 @@ package provider
 import "github.com/hashicorp/terraform-plugin-framework/types"
 
 type KustomizeProviderModel struct {
-	Kubernetes *KubernetesModel `tfsdk:"kubernetes"`
+	Kubernetes       *KubernetesModel `tfsdk:"kubernetes"`
+	FieldManager     types.String     `tfsdk:"field_manager"`
+	ForceConflicts   types.Bool       `tfsdk:"force_conflicts"`
+	Parallelism      types.Int64      `tfsdk:"parallelism"`
+	DefaultNamespace types.String     `tfsdk:"default_namespace"`
 }
 
 type KubernetesModel struct {
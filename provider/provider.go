@@ -8,6 +8,8 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/restmapper"
 	"sigs.k8s.io/kustomize/api/krusty"
 
 	"github.com/webzyno/terraform-provider-kustomize/client"
@@ -56,12 +58,32 @@ func (p *KustomizeProvider) Configure(ctx context.Context, req provider.Configur
 		}
 		clientSet.DynamicClient = dynamicClient
 
+		// Discovery is cached in memory and shared by all resources, the RESTMapper resets it when a kind is unknown
 		discoveryClient, err := newDiscoveryClient(*data.Kubernetes)
 		if err != nil {
 			resp.Diagnostics.AddWarning("Failed to create Kubernetes discovery client.", err.Error())
+		} else {
+			clientSet.DiscoveryClient = memory.NewMemCacheClient(discoveryClient)
+			clientSet.RESTMapper = restmapper.NewDeferredDiscoveryRESTMapper(clientSet.DiscoveryClient)
 		}
-		clientSet.DiscoveryClient = discoveryClient
+
+		defaultNamespace, err := newDefaultNamespace(*data.Kubernetes)
+		if err != nil {
+			resp.Diagnostics.AddWarning("Failed to get default namespace from Kubernetes configuration.", err.Error())
+		}
+		clientSet.DefaultNamespace = defaultNamespace
 	}
+	if data.DefaultNamespace.ValueString() != "" {
+		clientSet.DefaultNamespace = data.DefaultNamespace.ValueString()
+	}
+	if clientSet.DefaultNamespace == "" {
+		clientSet.DefaultNamespace = "default"
+	}
+
+	// Server-side apply defaults, kustomize_apply falls back to these when its own attributes are unset
+	clientSet.FieldManager = data.FieldManager.ValueString()
+	clientSet.ForceConflicts = data.ForceConflicts.ValueBool()
+	clientSet.Parallelism = int(data.Parallelism.ValueInt64())
 
 	p.clientSet = clientSet
 
@@ -79,5 +101,6 @@ func (p *KustomizeProvider) DataSources(_ context.Context) []func() datasource.D
 func (p *KustomizeProvider) Resources(_ context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		apply.NewKustomizeApply,
+		apply.NewKustomizeObject,
 	}
 }
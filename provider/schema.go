@@ -1,7 +1,9 @@
 package provider
 
 import (
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
@@ -14,6 +16,25 @@ Although there are existing providers, this provider gives you the best DX and m
 			Optional:    true,
 			Attributes:  kubernetesAttributes,
 		},
+		"default_namespace": schema.StringAttribute{
+			Description: "Namespace of namespaced objects in `kustomize_apply` and `kustomize_object` which don't set one. Defaults to the namespace of the kubeconfig context, or `default`.",
+			Optional:    true,
+		},
+		"field_manager": schema.StringAttribute{
+			Description: "Default field manager name used for server-side apply in `kustomize_apply` and `kustomize_object`. Defaults to `terraform-provider-kustomize`.",
+			Optional:    true,
+		},
+		"force_conflicts": schema.BoolAttribute{
+			Description: "Default for forcing server-side apply to take ownership of conflicting fields in `kustomize_apply` and `kustomize_object`.",
+			Optional:    true,
+		},
+		"parallelism": schema.Int64Attribute{
+			Description: "Default maximum number of objects applied concurrently by `kustomize_apply`. Defaults to `10`.",
+			Optional:    true,
+			Validators: []validator.Int64{
+				int64validator.AtLeast(1),
+			},
+		},
 	},
 }
 
@@ -1,97 +1,372 @@
 package virtfs
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
+	"github.com/samber/lo"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
 
 const KUSTOMIZATION = "kustomization.yaml"
 
 type OverlayFS struct {
-	memoryFS          filesys.FileSystem
-	diskFS            filesys.FileSystem
-	kustomizationPath string
+	memoryFS filesys.FileSystem
+	diskFS   filesys.FileSystem
+	baseDir  string
+	// removed are absolute paths removed from the disk layer, hiding everything below them on disk
+	removed map[string]bool
+	// sources are SHA-256 hashes of files read from the disk layer, keyed by absolute path
+	sources map[string]string
 }
 
-func NewOverlayFS() (filesys.FileSystem, error) {
-	wd, err := os.Getwd()
+// NewOverlayFS returns a copy-on-write file system which reads from disk, overlaid with files kept in memory such as
+// the generated kustomization.yaml in baseDir. All writes through the file system go to the memory layer, so a build
+// doesn't change the disk. Helm chart inflation runs helm with os and exec instead, which pulls charts to disk.
+// A relative baseDir is relative to the working directory.
+func NewOverlayFS(baseDir string) (*OverlayFS, error) {
+	baseDir, err := filepath.Abs(baseDir)
 	if err != nil {
 		return nil, err
 	}
+	// Disk paths are resolved with symlinks evaluated, memory paths must match them
+	if resolved, err := filepath.EvalSymlinks(baseDir); err == nil {
+		baseDir = resolved
+	}
 
 	return &OverlayFS{
-		memoryFS:          filesys.MakeFsInMemory(),
-		diskFS:            filesys.MakeFsOnDisk(),
-		kustomizationPath: filepath.Join(wd, KUSTOMIZATION),
+		memoryFS: filesys.MakeFsInMemory(),
+		diskFS:   filesys.MakeFsOnDisk(),
+		baseDir:  baseDir,
+		removed:  map[string]bool{},
+		sources:  map[string]string{},
 	}, nil
 }
 
+// BaseDir returns the absolute base directory, with symlinks evaluated.
+func (f *OverlayFS) BaseDir() string {
+	return f.baseDir
+}
+
+// WriteMemoryFile writes a file to the memory layer, where it hides a file on disk at the same path.
+// A relative path is relative to the base directory.
+func (f *OverlayFS) WriteMemoryFile(path string, data []byte) error {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(f.baseDir, path)
+	}
+	return f.memoryFS.WriteFile(filepath.Clean(path), data)
+}
+
+// memoryFile returns the absolute path of path and whether it is a file in the memory layer.
+func (f *OverlayFS) memoryFile(path string) (string, bool) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path, false
+	}
+	return abs, f.memoryFS.Exists(abs) && !f.memoryFS.IsDir(abs)
+}
+
+// memoryDir returns the absolute path of path and whether it is a directory in the memory layer.
+// Parent directories of memory files are directories in the memory layer too.
+func (f *OverlayFS) memoryDir(path string) (string, bool) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path, false
+	}
+	return abs, f.memoryFS.IsDir(abs)
+}
+
+// onDisk reports whether path may be read from the disk layer, i.e. neither path nor one of its parents was removed.
+func (f *OverlayFS) onDisk(path string) bool {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return true
+	}
+	for {
+		if f.removed[abs] {
+			return false
+		}
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			return true
+		}
+		abs = parent
+	}
+}
+
+func (f *OverlayFS) diskExists(path string) bool {
+	return f.onDisk(path) && f.diskFS.Exists(path)
+}
+
+func (f *OverlayFS) diskIsDir(path string) bool {
+	return f.onDisk(path) && f.diskFS.IsDir(path)
+}
+
+func notExistError(op string, path string) error {
+	return &fs.PathError{Op: op, Path: path, Err: fs.ErrNotExist}
+}
+
+// stat returns file info from the memory layer, falling back to disk.
+func (f *OverlayFS) stat(path string) (fs.FileInfo, error) {
+	abs, isFile := f.memoryFile(path)
+	_, isDir := f.memoryDir(path)
+	if !isFile && (!isDir || f.diskIsDir(path)) {
+		if !f.onDisk(path) {
+			return nil, notExistError("lstat", path)
+		}
+		return os.Lstat(path)
+	}
+	file, err := f.memoryFS.Open(abs)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return file.Stat()
+}
+
 func (f *OverlayFS) Create(path string) (filesys.File, error) {
-	return f.diskFS.Create(path)
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	return f.memoryFS.Create(abs)
 }
 
 func (f *OverlayFS) Mkdir(path string) error {
-	return f.diskFS.Mkdir(path)
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	return f.memoryFS.Mkdir(abs)
 }
 
 func (f *OverlayFS) MkdirAll(path string) error {
-	return f.diskFS.MkdirAll(path)
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	return f.memoryFS.MkdirAll(abs)
 }
 
+// RemoveAll removes path from the memory layer and hides it on disk.
+// Temporary directories created by kustomize, such as clones of remote bases, are removed from disk.
 func (f *OverlayFS) RemoveAll(path string) error {
-	return f.diskFS.RemoveAll(path)
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if isKustomizeTempDir(abs) {
+		return f.diskFS.RemoveAll(abs)
+	}
+	if err := f.memoryFS.RemoveAll(abs); err != nil {
+		return err
+	}
+	f.removed[abs] = true
+	return nil
+}
+
+// isKustomizeTempDir reports whether path is a directory created by filesys.NewTmpConfirmedDir.
+func isKustomizeTempDir(path string) bool {
+	tempDir, err := filepath.EvalSymlinks(os.TempDir())
+	if err != nil {
+		tempDir = os.TempDir()
+	}
+	return filepath.Dir(path) == filepath.Clean(tempDir) && strings.HasPrefix(filepath.Base(path), "kustomize-")
 }
 
 func (f *OverlayFS) Open(path string) (filesys.File, error) {
-	return f.diskFS.Open(path)
+	if abs, ok := f.memoryFile(path); ok {
+		return f.memoryFS.Open(abs)
+	}
+	if !f.onDisk(path) {
+		return nil, notExistError("open", path)
+	}
+	file, err := f.diskFS.Open(path)
+	if err == nil {
+		if data, err := os.ReadFile(path); err == nil {
+			f.recordSource(path, data)
+		}
+	}
+	return file, err
 }
 
 func (f *OverlayFS) IsDir(path string) bool {
-	return f.diskFS.IsDir(path)
+	if _, ok := f.memoryDir(path); ok {
+		return true
+	}
+	return f.diskIsDir(path)
 }
 
+// ReadDir returns the names of entries in both layers, sorted by name.
 func (f *OverlayFS) ReadDir(path string) ([]string, error) {
-	return f.diskFS.ReadDir(path)
+	abs, ok := f.memoryDir(path)
+	if !ok && !f.onDisk(path) {
+		return nil, notExistError("open", path)
+	}
+	var names []string
+	if ok {
+		memoryNames, err := f.memoryFS.ReadDir(abs)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, memoryNames...)
+	}
+	if !ok || f.diskIsDir(path) {
+		diskNames, err := f.diskFS.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		// Entries removed from the disk layer are hidden
+		names = append(names, lo.Filter(diskNames, func(name string, _ int) bool { return f.onDisk(filepath.Join(path, name)) })...)
+	}
+	names = lo.Uniq(names)
+	sort.Strings(names)
+	return names, nil
 }
 
 func (f *OverlayFS) CleanedAbs(path string) (filesys.ConfirmedDir, string, error) {
-	if path == f.kustomizationPath {
-		_, _, err := f.memoryFS.CleanedAbs(".")
-		if err != nil {
-			return "", "", err
-		}
-		return filesys.ConfirmedDir(filepath.Dir(f.kustomizationPath)), KUSTOMIZATION, err
+	if abs, ok := f.memoryFile(path); ok {
+		return filesys.ConfirmedDir(filepath.Dir(abs)), filepath.Base(abs), nil
+	}
+	if abs, ok := f.memoryDir(path); ok && !f.diskExists(path) {
+		return filesys.ConfirmedDir(abs), "", nil
+	}
+	if !f.onDisk(path) {
+		return "", "", notExistError("stat", path)
 	}
 	return f.diskFS.CleanedAbs(path)
 }
 
 func (f *OverlayFS) Exists(path string) bool {
-	if path == f.kustomizationPath {
-		return f.memoryFS.Exists(KUSTOMIZATION)
+	if _, ok := f.memoryFile(path); ok {
+		return true
+	}
+	if _, ok := f.memoryDir(path); ok {
+		return true
 	}
-	return f.diskFS.Exists(path)
+	return f.diskExists(path)
 }
 
+// Glob returns the files matching pattern in both layers, sorted by name.
+// Matches in the memory layer are returned relative to the working directory if pattern is relative.
 func (f *OverlayFS) Glob(pattern string) ([]string, error) {
-	return f.diskFS.Glob(pattern)
+	matches, err := f.diskFS.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	matches = lo.Filter(matches, func(match string, _ int) bool { return f.onDisk(match) })
+	absPattern, err := filepath.Abs(pattern)
+	if err != nil {
+		return nil, err
+	}
+	memoryMatches, err := f.memoryFS.Glob(absPattern)
+	if err != nil {
+		return nil, err
+	}
+	for _, match := range memoryMatches {
+		if !filepath.IsAbs(pattern) {
+			if match, err = filepath.Rel(filepath.Dir(absPattern), match); err != nil {
+				return nil, err
+			}
+			match = filepath.Join(filepath.Dir(pattern), match)
+		}
+		matches = append(matches, match)
+	}
+	matches = lo.Uniq(matches)
+	sort.Strings(matches)
+	return matches, nil
 }
 
 func (f *OverlayFS) ReadFile(path string) ([]byte, error) {
-	if path == f.kustomizationPath {
-		return f.memoryFS.ReadFile(KUSTOMIZATION)
+	if abs, ok := f.memoryFile(path); ok {
+		return f.memoryFS.ReadFile(abs)
 	}
-	return f.diskFS.ReadFile(path)
+	if !f.onDisk(path) {
+		return nil, notExistError("open", path)
+	}
+	data, err := f.diskFS.ReadFile(path)
+	if err == nil {
+		f.recordSource(path, data)
+	}
+	return data, err
+}
+
+func (f *OverlayFS) recordSource(path string, data []byte) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return
+	}
+	sum := sha256.Sum256(data)
+	f.sources[abs] = hex.EncodeToString(sum[:])
+}
+
+// Sources returns SHA-256 hashes of files read from disk, keyed by path relative to the base directory.
+// Files which no longer exist, such as those in removed clones of remote bases, are left out.
+func (f *OverlayFS) Sources() map[string]string {
+	sources := map[string]string{}
+	for abs, hash := range f.sources {
+		if _, err := os.Stat(abs); err != nil {
+			continue
+		}
+		path, err := filepath.Rel(f.baseDir, abs)
+		if err != nil {
+			path = abs
+		}
+		sources[filepath.ToSlash(path)] = hash
+	}
+	return sources
 }
 
 func (f *OverlayFS) WriteFile(path string, data []byte) error {
-	if path == KUSTOMIZATION {
-		return f.memoryFS.WriteFile(KUSTOMIZATION, data)
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
 	}
-	return f.diskFS.WriteFile(path, data)
+	return f.memoryFS.WriteFile(abs, data)
 }
 
+// Walk walks the merged tree of both layers in lexical order, like filepath.Walk.
 func (f *OverlayFS) Walk(path string, walkFn filepath.WalkFunc) error {
-	return f.diskFS.Walk(path, walkFn)
+	info, err := f.stat(path)
+	if err != nil {
+		err = walkFn(path, nil, err)
+	} else {
+		err = f.walk(path, info, walkFn)
+	}
+	if err == filepath.SkipDir || err == filepath.SkipAll {
+		return nil
+	}
+	return err
+}
+
+func (f *OverlayFS) walk(path string, info fs.FileInfo, walkFn filepath.WalkFunc) error {
+	if !info.IsDir() {
+		return walkFn(path, info, nil)
+	}
+
+	names, err := f.ReadDir(path)
+	if walkErr := walkFn(path, info, err); err != nil || walkErr != nil {
+		return walkErr
+	}
+	for _, name := range names {
+		filename := filepath.Join(path, name)
+		fileInfo, err := f.stat(filename)
+		if err != nil {
+			if err := walkFn(filename, fileInfo, err); err != nil && err != filepath.SkipDir {
+				return err
+			}
+			continue
+		}
+		if err := f.walk(filename, fileInfo, walkFn); err != nil {
+			if !fileInfo.IsDir() || err != filepath.SkipDir {
+				return err
+			}
+		}
+	}
+	return nil
 }
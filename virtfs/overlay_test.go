@@ -0,0 +1,171 @@
+package virtfs
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// newTestOverlayFS returns an OverlayFS over a temporary directory holding base/deployment.yaml and
+// base/kustomization.yaml on disk, with base/kustomization.yaml and base/generated/patch.yaml in memory.
+func newTestOverlayFS(t *testing.T) *OverlayFS {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "base"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"base/deployment.yaml":    "kind: Deployment\n",
+		"base/kustomization.yaml": "resources: [deployment.yaml]\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	overlay, err := NewOverlayFS(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := overlay.WriteMemoryFile("base/kustomization.yaml", []byte("resources: []\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := overlay.WriteMemoryFile("base/generated/patch.yaml", []byte("kind: Patch\n")); err != nil {
+		t.Fatal(err)
+	}
+	return overlay
+}
+
+func TestOverlayFSReadDir(t *testing.T) {
+	overlay := newTestOverlayFS(t)
+	names, err := overlay.ReadDir(filepath.Join(overlay.BaseDir(), "base"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"deployment.yaml", "generated", "kustomization.yaml"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("ReadDir() = %v, want %v", names, want)
+	}
+}
+
+func TestOverlayFSGlob(t *testing.T) {
+	overlay := newTestOverlayFS(t)
+	base := filepath.Join(overlay.BaseDir(), "base")
+	matches, err := overlay.Glob(filepath.Join(base, "*.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(base, "deployment.yaml"), filepath.Join(base, "kustomization.yaml")}
+	if !reflect.DeepEqual(matches, want) {
+		t.Errorf("Glob() = %v, want %v", matches, want)
+	}
+
+	matches, err = overlay.Glob(filepath.Join(base, "generated", "*.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []string{filepath.Join(base, "generated", "patch.yaml")}
+	if !reflect.DeepEqual(matches, want) {
+		t.Errorf("Glob() = %v, want %v", matches, want)
+	}
+}
+
+func TestOverlayFSWalk(t *testing.T) {
+	overlay := newTestOverlayFS(t)
+	var paths []string
+	err := overlay.Walk(overlay.BaseDir(), func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(overlay.BaseDir(), path)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{".", "base", "base/deployment.yaml", "base/generated", "base/generated/patch.yaml", "base/kustomization.yaml"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("Walk() visited %v, want %v", paths, want)
+	}
+}
+
+func TestOverlayFSReadFile(t *testing.T) {
+	overlay := newTestOverlayFS(t)
+	base := filepath.Join(overlay.BaseDir(), "base")
+
+	data, err := overlay.ReadFile(filepath.Join(base, "kustomization.yaml"))
+	if err != nil || string(data) != "resources: []\n" {
+		t.Errorf("ReadFile() = %q (%v), want the memory file", data, err)
+	}
+	if _, err := overlay.ReadFile(filepath.Join(base, "deployment.yaml")); err != nil {
+		t.Fatal(err)
+	}
+
+	sources := overlay.Sources()
+	if _, found := sources["base/deployment.yaml"]; !found || len(sources) != 1 {
+		t.Errorf("Sources() = %v, want only base/deployment.yaml", sources)
+	}
+}
+
+func TestOverlayFSCopyOnWrite(t *testing.T) {
+	overlay := newTestOverlayFS(t)
+	deployment := filepath.Join(overlay.BaseDir(), "base", "deployment.yaml")
+
+	if err := overlay.WriteFile(deployment, []byte("kind: StatefulSet\n")); err != nil {
+		t.Fatal(err)
+	}
+	data, err := overlay.ReadFile(deployment)
+	if err != nil || string(data) != "kind: StatefulSet\n" {
+		t.Errorf("ReadFile() = %q (%v), want the written content", data, err)
+	}
+	disk, err := os.ReadFile(deployment)
+	if err != nil || string(disk) != "kind: Deployment\n" {
+		t.Errorf("file on disk = %q (%v), want it unchanged", disk, err)
+	}
+}
+
+func TestOverlayFSRemoveAll(t *testing.T) {
+	overlay := newTestOverlayFS(t)
+	base := filepath.Join(overlay.BaseDir(), "base")
+
+	if err := overlay.RemoveAll(base); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{base, filepath.Join(base, "deployment.yaml"), filepath.Join(base, "generated", "patch.yaml")} {
+		if overlay.Exists(path) {
+			t.Errorf("%s exists after RemoveAll", path)
+		}
+	}
+	if _, err := overlay.ReadFile(filepath.Join(base, "deployment.yaml")); !os.IsNotExist(err) {
+		t.Errorf("ReadFile() error = %v, want not exist", err)
+	}
+	names, err := overlay.ReadDir(overlay.BaseDir())
+	if err != nil || len(names) != 0 {
+		t.Errorf("ReadDir() = %v (%v), want no entries", names, err)
+	}
+	if _, err := os.Stat(filepath.Join(base, "deployment.yaml")); err != nil {
+		t.Errorf("file on disk was removed: %v", err)
+	}
+}
+
+func TestOverlayFSRemoveAllKustomizeTempDir(t *testing.T) {
+	overlay := newTestOverlayFS(t)
+	tempDir, err := os.MkdirTemp("", "kustomize-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(tempDir) })
+
+	if err := overlay.RemoveAll(tempDir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(tempDir); !os.IsNotExist(err) {
+		t.Errorf("temporary directory of kustomize wasn't removed from disk: %v", err)
+	}
+}